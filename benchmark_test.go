@@ -0,0 +1,322 @@
+package dependency_injection
+
+import (
+	"testing"
+)
+
+// Benchmarks of resolutions, run them with:
+//
+//	go test -run '^$' -bench . -benchmem -count 5 > new.txt
+//	go run ./cmd/di-benchcmp benchmarks/baseline.txt new.txt
+
+// chainEnd is the last service of a dependency chain.
+type chainEnd struct{}
+
+// chainLink is a service of a dependency chain that depends on T.
+type chainLink[T any] struct {
+	next T
+}
+
+type (
+	chain1  = chainLink[chainEnd]
+	chain2  = chainLink[chain1]
+	chain3  = chainLink[chain2]
+	chain4  = chainLink[chain3]
+	chain5  = chainLink[chain4]
+	chain6  = chainLink[chain5]
+	chain7  = chainLink[chain6]
+	chain8  = chainLink[chain7]
+	chain9  = chainLink[chain8]
+	chain10 = chainLink[chain9]
+)
+
+// addChainLink registers a link of a dependency chain which depends on T with given lifetime.
+func addChainLink[T any](collection *ServiceCollection, lifetime int) {
+	collection.add(getReflectType[chainLink[T]](), lifetime, func(s *Scope) any {
+		next, _ := GetService[T](s)
+		return chainLink[T]{next: next}
+	})
+}
+
+// initChainCollection registers a dependency chain of 10 services with given lifetime.
+func initChainCollection(lifetime int) *ServiceCollection {
+	collection := InitServiceCollection()
+
+	collection.add(getReflectType[chainEnd](), lifetime, func(s *Scope) any {
+		return chainEnd{}
+	})
+	addChainLink[chainEnd](collection, lifetime)
+	addChainLink[chain1](collection, lifetime)
+	addChainLink[chain2](collection, lifetime)
+	addChainLink[chain3](collection, lifetime)
+	addChainLink[chain4](collection, lifetime)
+	addChainLink[chain5](collection, lifetime)
+	addChainLink[chain6](collection, lifetime)
+	addChainLink[chain7](collection, lifetime)
+	addChainLink[chain8](collection, lifetime)
+	addChainLink[chain9](collection, lifetime)
+
+	collection.Lock()
+
+	return collection
+}
+
+// wideLeaf is a dependency of wideRoot, T makes the leaves distinct types.
+type wideLeaf[T any] struct{}
+
+// wideRoot is a service with many direct dependencies.
+type wideRoot struct{}
+
+// addWideLeaf registers a leaf of a wide graph and returns a function that resolves it.
+func addWideLeaf[T any](collection *ServiceCollection) func(s *Scope) {
+	_ = AddScoped[wideLeaf[T]](collection, func(s *Scope) any {
+		return wideLeaf[T]{}
+	})
+
+	return func(s *Scope) {
+		_, _ = GetService[wideLeaf[T]](s)
+	}
+}
+
+// initWideCollection registers a transient service which depends on 16 scoped services.
+func initWideCollection() *ServiceCollection {
+	collection := InitServiceCollection()
+
+	leaves := []func(s *Scope){
+		addWideLeaf[[1]byte](collection), addWideLeaf[[2]byte](collection),
+		addWideLeaf[[3]byte](collection), addWideLeaf[[4]byte](collection),
+		addWideLeaf[[5]byte](collection), addWideLeaf[[6]byte](collection),
+		addWideLeaf[[7]byte](collection), addWideLeaf[[8]byte](collection),
+		addWideLeaf[[9]byte](collection), addWideLeaf[[10]byte](collection),
+		addWideLeaf[[11]byte](collection), addWideLeaf[[12]byte](collection),
+		addWideLeaf[[13]byte](collection), addWideLeaf[[14]byte](collection),
+		addWideLeaf[[15]byte](collection), addWideLeaf[[16]byte](collection),
+	}
+	_ = AddTransient[wideRoot](collection, func(s *Scope) any {
+		for _, leaf := range leaves {
+			leaf(s)
+		}
+		return wideRoot{}
+	})
+
+	collection.Lock()
+
+	return collection
+}
+
+// initLifetimeCollection registers a singleton, a scoped and a transient service.
+func initLifetimeCollection() *ServiceCollection {
+	collection := InitServiceCollection()
+
+	_ = AddSingleton[TestConfig](collection, func(s *Scope) any {
+		return TestConfig{}
+	})
+	_ = AddScoped[*TestRepository](collection, func(s *Scope) any {
+		return &TestRepository{}
+	})
+	_ = AddTransient[*TestBuffer](collection, func(s *Scope) any {
+		return &TestBuffer{}
+	})
+
+	collection.Lock()
+
+	return collection
+}
+
+// BenchmarkWarmResolution measures resolutions of services which are already initialized in the scope or collection.
+// Transient resolutions allocate the scope of the provider and the instance.
+func BenchmarkWarmResolution(b *testing.B) {
+	collection := initLifetimeCollection()
+
+	scope, _ := collection.CreateScope()
+	defer scope.Close()
+
+	b.Run("singleton", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_, _ = GetService[TestConfig](scope)
+		}
+	})
+
+	b.Run("scoped", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_, _ = GetService[*TestRepository](scope)
+		}
+	})
+
+	b.Run("transient", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_, _ = GetService[*TestBuffer](scope)
+		}
+	})
+}
+
+// BenchmarkColdResolution measures first resolutions of services in a new scope, including creating and closing it.
+// Singletons are invalidated before each resolution.
+func BenchmarkColdResolution(b *testing.B) {
+	collection := initLifetimeCollection()
+
+	b.Run("singleton", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = Invalidate[TestConfig](collection)
+			scope, _ := collection.CreateScope()
+			_, _ = GetService[TestConfig](scope)
+			_ = scope.Close()
+		}
+	})
+
+	b.Run("scoped", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			scope, _ := collection.CreateScope()
+			_, _ = GetService[*TestRepository](scope)
+			_ = scope.Close()
+		}
+	})
+
+	b.Run("transient", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			scope, _ := collection.CreateScope()
+			_, _ = GetService[*TestBuffer](scope)
+			_ = scope.Close()
+		}
+	})
+}
+
+// BenchmarkDeepChain measures resolutions of a service at the top of a dependency chain of 10 services.
+func BenchmarkDeepChain(b *testing.B) {
+	b.Run("transient", func(b *testing.B) {
+		collection := initChainCollection(TRANSIENT)
+		scope, _ := collection.CreateScope()
+		defer scope.Close()
+
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			_, _ = GetService[chain10](scope)
+		}
+	})
+
+	b.Run("scoped", func(b *testing.B) {
+		collection := initChainCollection(SCOPED)
+
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			scope, _ := collection.CreateScope()
+			_, _ = GetService[chain10](scope)
+			_ = scope.Close()
+		}
+	})
+}
+
+// BenchmarkWideGraph measures resolutions of a service which depends on 16 scoped services in a new scope.
+func BenchmarkWideGraph(b *testing.B) {
+	collection := initWideCollection()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		scope, _ := collection.CreateScope()
+		_, _ = GetService[wideRoot](scope)
+		_ = scope.Close()
+	}
+}
+
+// BenchmarkParallelResolution measures resolutions of services from a shared scope by GOMAXPROCS goroutines.
+func BenchmarkParallelResolution(b *testing.B) {
+	collection := initLifetimeCollection()
+
+	scope, _ := collection.CreateScope()
+	defer scope.Close()
+
+	b.Run("singleton", func(b *testing.B) {
+		b.ReportAllocs()
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				_, _ = GetService[TestConfig](scope)
+			}
+		})
+	})
+
+	b.Run("scoped", func(b *testing.B) {
+		b.ReportAllocs()
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				_, _ = GetService[*TestRepository](scope)
+			}
+		})
+	})
+
+	b.Run("transient", func(b *testing.B) {
+		b.ReportAllocs()
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				_, _ = GetService[*TestBuffer](scope)
+			}
+		})
+	})
+}
+
+// BenchmarkCreateScope measures creating and closing a scope without resolving any service.
+func BenchmarkCreateScope(b *testing.B) {
+	collection := initLifetimeCollection()
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		scope, _ := collection.CreateScope()
+		_ = scope.Close()
+	}
+}
+
+func TestBenchmarkCollections(t *testing.T) {
+	for _, lifetime := range []int{SCOPED, TRANSIENT} {
+		scope, err := initChainCollection(lifetime).CreateScope()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		chain, err := GetService[chain10](scope)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_ = chain.next.next.next.next.next.next.next.next.next.next
+	}
+
+	scope, err := initWideCollection().CreateScope()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = GetService[wideRoot](scope); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// BenchmarkRequest measures a request which creates a scope, resolves services and closes the scope, so allocations
+// per request are reported. Scoped services are stored in the scope only when the request resolves them.
+func BenchmarkRequest(b *testing.B) {
+	collection := initLifetimeCollection()
+
+	b.Run("singleton", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			scope, _ := collection.CreateScope()
+			_, _ = GetService[TestConfig](scope)
+			_ = scope.Close()
+		}
+	})
+
+	b.Run("scoped", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			scope, _ := collection.CreateScope()
+			_, _ = GetService[TestConfig](scope)
+			_, _ = GetService[*TestRepository](scope)
+			_ = scope.Close()
+		}
+	})
+}
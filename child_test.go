@@ -0,0 +1,122 @@
+package dependency_injection
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+type TestConfig struct {
+	tenant string
+}
+
+type TestRepository struct {
+	config TestConfig
+}
+
+func TestGetServiceFromChildCollection(t *testing.T) {
+	parent := InitServiceCollection()
+
+	repositoryCounter := 0
+
+	err := AddSingleton[TestConfig](parent, func(s *Scope) any {
+		return TestConfig{tenant: "default"}
+	})
+	assert.Nil(t, err)
+	err = AddSingleton[*TestRepository](parent, func(s *Scope) any {
+		repositoryCounter++
+		config, _ := GetService[TestConfig](s)
+		return &TestRepository{config: config}
+	})
+	assert.Nil(t, err)
+
+	parent.Lock()
+
+	// Registering in child is possible after locking parent
+	child := parent.CreateChild()
+	err = AddSingleton[TestConfig](child, func(s *Scope) any {
+		return TestConfig{tenant: "tenant-1"}
+	})
+	assert.Nil(t, err)
+
+	child.Lock()
+
+	parentScope, err := parent.CreateScope()
+	assert.Nil(t, err)
+	childScope, err := child.CreateScope()
+	assert.Nil(t, err)
+
+	// Overridden services are provided from the child
+	parentConfig, err := GetService[TestConfig](parentScope)
+	assert.Nil(t, err)
+	assert.Equal(t, "default", parentConfig.tenant)
+
+	childConfig, err := GetService[TestConfig](childScope)
+	assert.Nil(t, err)
+	assert.Equal(t, "tenant-1", childConfig.tenant)
+
+	// Inherited singletons are shared with the parent and initialized in the parent
+	childRepository, err := GetService[*TestRepository](childScope)
+	assert.Nil(t, err)
+	assert.Equal(t, "default", childRepository.config.tenant)
+
+	parentRepository, err := GetService[*TestRepository](parentScope)
+	assert.Nil(t, err)
+	assert.Same(t, parentRepository, childRepository)
+	assert.Equal(t, 1, repositoryCounter)
+}
+
+func TestOverriddenSingletonInChildCollection(t *testing.T) {
+	parent := InitServiceCollection()
+
+	err := AddSingleton[*TestRepository](parent, func(s *Scope) any {
+		return &TestRepository{config: TestConfig{tenant: "default"}}
+	})
+	assert.Nil(t, err)
+
+	parent.Lock()
+
+	child := parent.CreateChild()
+	err = AddSingleton[*TestRepository](child, func(s *Scope) any {
+		return &TestRepository{config: TestConfig{tenant: "tenant-1"}}
+	})
+	assert.Nil(t, err)
+
+	child.Lock()
+
+	parentScope, err := parent.CreateScope()
+	assert.Nil(t, err)
+	childScope1, err := child.CreateScope()
+	assert.Nil(t, err)
+	childScope2, err := child.CreateScope()
+	assert.Nil(t, err)
+
+	parentRepository, err := GetService[*TestRepository](parentScope)
+	assert.Nil(t, err)
+	childRepository1, err := GetService[*TestRepository](childScope1)
+	assert.Nil(t, err)
+	childRepository2, err := GetService[*TestRepository](childScope2)
+	assert.Nil(t, err)
+
+	assert.NotSame(t, parentRepository, childRepository1)
+	assert.Same(t, childRepository1, childRepository2)
+	assert.Equal(t, "tenant-1", childRepository1.config.tenant)
+}
+
+func TestCreateScopeOnChildOfUnlockedCollection(t *testing.T) {
+	parent := InitServiceCollection()
+	child := parent.CreateChild()
+
+	child.Lock()
+
+	scope, err := child.CreateScope()
+	assert.Nil(t, scope)
+	assert.NotNil(t, err)
+	assert.Equal(t, fmt.Errorf("you have to lock parent service collection to create a scope"), err)
+
+	parent.Lock()
+
+	scope, err = child.CreateScope()
+	assert.Nil(t, err)
+	assert.NotNil(t, scope)
+}
@@ -0,0 +1,154 @@
+package dependency_injection
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// cloneOptions are the options of Clone.
+type cloneOptions struct {
+	shareSingletons bool
+}
+
+// CloneOption configures optional behaviours of Clone.
+type CloneOption func(options *cloneOptions)
+
+// ShareSingletons shares the singletons which are already initialized in the collection with the clone,
+// singletons which are initialized after cloning are not shared. The clone doesn't dispose the shared singletons
+// when they get expired or invalidated in the clone, since the collection still serves them.
+func ShareSingletons() CloneOption {
+	return func(options *cloneOptions) {
+		options.shareSingletons = true
+	}
+}
+
+// Clone creates a new unlocked collection with a copy of the registrations of the collection,
+// so registrations can be added to the clone without touching the collection.
+// The clone has the same parent, modules, logger and observers, but it has its own metrics and object pools.
+// Singletons are initialized again in the clone unless ShareSingletons option is given.
+func (collection *ServiceCollection) Clone(opts ...CloneOption) *ServiceCollection {
+	options := cloneOptions{}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	clone := InitServiceCollection()
+	clone.parent = collection.parent
+	clone.logger = collection.logger
+	clone.configSources = append([]ConfigSource(nil), collection.configSources...)
+
+	for name := range collection.installedModules {
+		clone.installedModules[name] = true
+	}
+
+	for _, observer := range collection.observers {
+		if observer != Observer(collection.metrics) {
+			clone.observers = append(clone.observers, observer)
+		}
+	}
+	if collection.metrics != nil {
+		_ = clone.EnableMetrics()
+	}
+	if collection.activeScopes != nil {
+		_ = clone.EnableScopeTracking()
+	}
+
+	for t, serviceType := range collection.registrations() {
+		clone.registeredServicePool[t] = serviceType.copyFor(clone)
+	}
+
+	if options.shareSingletons {
+		collection.mutex.RLock()
+		for t, value := range collection.singletonServicePool {
+			if serviceType, exists := clone.registeredServicePool[t]; exists {
+				clone.singletonServicePool[t] = value
+				serviceType.storeInstance(value)
+				serviceType.borrowed = true
+				serviceType.expiresAt = collection.registrations()[t].expiresAt
+			}
+		}
+		collection.mutex.RUnlock()
+	}
+
+	return clone
+}
+
+// Snapshot is a copy of the registrations and installed modules of a collection which can be restored later.
+type Snapshot struct {
+	registrations    map[reflect.Type]*ServiceType
+	installedModules map[string]bool
+}
+
+// Snapshot returns a copy of the current registrations of the collection.
+func (collection *ServiceCollection) Snapshot() *Snapshot {
+	snapshot := &Snapshot{
+		registrations:    make(map[reflect.Type]*ServiceType),
+		installedModules: make(map[string]bool),
+	}
+
+	for t, serviceType := range collection.registeredServicePool {
+		snapshot.registrations[t] = serviceType.copyFor(collection)
+	}
+	for name := range collection.installedModules {
+		snapshot.installedModules[name] = true
+	}
+
+	return snapshot
+}
+
+// Restore replaces the registrations of the collection with a snapshot of it, it can't be restored after locking
+// the collection. A snapshot can be restored multiple times.
+func (collection *ServiceCollection) Restore(snapshot *Snapshot) error {
+	if err := collection.checkLock(); err != nil {
+		return err
+	}
+
+	for _, serviceType := range snapshot.registrations {
+		if serviceType.collection != collection {
+			return fmt.Errorf("snapshot is taken from an other service collection")
+		}
+	}
+
+	collection.registeredServicePool = make(map[reflect.Type]*ServiceType)
+	for t, serviceType := range snapshot.registrations {
+		collection.registeredServicePool[t] = serviceType.copyFor(collection)
+	}
+
+	collection.installedModules = make(map[string]bool)
+	for name := range snapshot.installedModules {
+		collection.installedModules[name] = true
+	}
+
+	return nil
+}
+
+// copyFor returns a copy of the registration of the service which is owned by given collection.
+// Runtime state of the service, like its object pool and expiration time, isn't copied.
+func (serviceType *ServiceType) copyFor(collection *ServiceCollection) *ServiceType {
+	copied := &ServiceType{
+		reflectType:          serviceType.reflectType,
+		id:                   serviceType.id,
+		lifetime:             serviceType.lifetime,
+		provider:             serviceType.provider,
+		collection:           collection,
+		module:               serviceType.module,
+		poolSize:             serviceType.poolSize,
+		reset:                serviceType.reset,
+		ttl:                  serviceType.ttl,
+		dispose:              serviceType.dispose,
+		retryPolicy:          serviceType.retryPolicy,
+		declaredDependencies: append([]reflect.Type(nil), serviceType.declaredDependencies...),
+	}
+
+	if serviceType.lifetime == POOLED {
+		copied.pool = newObjectPool(serviceType.poolSize)
+	}
+
+	serviceType.dependencyMutex.Lock()
+	for dependency := range serviceType.recordedDependencies {
+		copied.recordDependency(dependency)
+	}
+	serviceType.dependencyMutex.Unlock()
+
+	return copied
+}
@@ -0,0 +1,216 @@
+package dependency_injection
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestClone(t *testing.T) {
+	collection := InitServiceCollection()
+
+	err := AddSingleton[TestConfig](collection, func(s *Scope) any {
+		return TestConfig{tenant: "base"}
+	})
+	assert.Nil(t, err)
+	err = AddPooled[*TestBuffer](collection, func(s *Scope) any {
+		return &TestBuffer{}
+	}, nil, WithPoolSize(2))
+	assert.Nil(t, err)
+
+	clone := collection.Clone()
+	assert.False(t, clone.locked)
+
+	err = AddScoped[*TestRepository](clone, func(s *Scope) any {
+		config, _ := GetService[TestConfig](s)
+		return &TestRepository{config: config}
+	})
+	assert.Nil(t, err)
+
+	collection.Lock()
+	clone.Lock()
+
+	cloneScope, err := clone.CreateScope()
+	assert.Nil(t, err)
+	repository, err := GetService[*TestRepository](cloneScope)
+	assert.Nil(t, err)
+	assert.Equal(t, "base", repository.config.tenant)
+	_, err = GetService[*TestBuffer](cloneScope)
+	assert.Nil(t, err)
+	assert.Nil(t, cloneScope.Close())
+
+	scope, err := collection.CreateScope()
+	assert.Nil(t, err)
+	defer scope.Close()
+
+	_, err = GetService[*TestRepository](scope)
+	assert.NotNil(t, err, "registrations of the clone should not be added to the collection")
+
+	stats, err := GetPoolStats[*TestBuffer](collection)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, stats.Idle, "object pools should not be shared")
+	assert.Equal(t, 0, len(collection.singletonServicePool), "singletons should not be shared")
+}
+
+func TestReplace(t *testing.T) {
+	collection := InitServiceCollection()
+
+	err := AddSingleton[TestConfig](collection, func(s *Scope) any {
+		return TestConfig{tenant: "real"}
+	})
+	assert.Nil(t, err)
+
+	collection.Lock()
+
+	scope, err := collection.CreateScope()
+	assert.Nil(t, err)
+	defer scope.Close()
+
+	config, _ := GetService[TestConfig](scope)
+	assert.Equal(t, "real", config.tenant)
+
+	restore, err := collection.replace(getReflectType[TestConfig](), func(s *Scope) any {
+		return TestConfig{tenant: "fake"}
+	})
+	assert.Nil(t, err)
+
+	config, _ = GetService[TestConfig](scope)
+	assert.Equal(t, "fake", config.tenant)
+
+	restore()
+
+	config, _ = GetService[TestConfig](scope)
+	assert.Equal(t, "real", config.tenant)
+
+	_, err = collection.replace(getReflectType[*TestRepository](), func(s *Scope) any {
+		return &TestRepository{}
+	})
+	assert.NotNil(t, err)
+}
+
+func TestCloneShareSingletons(t *testing.T) {
+	collection := InitServiceCollection()
+
+	calls := 0
+	err := AddSingleton[*TestRepository](collection, func(s *Scope) any {
+		calls++
+		return &TestRepository{}
+	})
+	assert.Nil(t, err)
+
+	collection.Lock()
+
+	scope, err := collection.CreateScope()
+	assert.Nil(t, err)
+	defer scope.Close()
+
+	repository, err := GetService[*TestRepository](scope)
+	assert.Nil(t, err)
+
+	for _, shared := range []bool{true, false} {
+		opts := make([]CloneOption, 0)
+		if shared {
+			opts = append(opts, ShareSingletons())
+		}
+
+		clone := collection.Clone(opts...)
+		clone.Lock()
+
+		cloneScope, err := clone.CreateScope()
+		assert.Nil(t, err)
+
+		cloneRepository, err := GetService[*TestRepository](cloneScope)
+		assert.Nil(t, err)
+		assert.Equal(t, shared, repository == cloneRepository)
+		assert.Nil(t, cloneScope.Close())
+	}
+
+	assert.Equal(t, 2, calls)
+}
+
+func TestCloneDoesNotDisposeSharedSingletons(t *testing.T) {
+	current := time.Now()
+	setNow(t, &current)
+
+	collection := InitServiceCollection()
+
+	counter := 0
+	disposed := make([]int, 0)
+	err := AddSingletonWithTTL[*TestType](collection, time.Minute, func(s *Scope) any {
+		counter++
+		return &TestType{counter: counter}
+	}, WithDispose(func(value *TestType) {
+		disposed = append(disposed, value.counter)
+	}))
+	assert.Nil(t, err)
+
+	collection.Lock()
+
+	scope, err := collection.CreateScope()
+	assert.Nil(t, err)
+	defer scope.Close()
+
+	shared, err := GetService[*TestType](scope)
+	assert.Nil(t, err)
+
+	for _, expire := range []bool{true, false} {
+		clone := collection.Clone(ShareSingletons())
+		clone.Lock()
+
+		cloneScope, err := clone.CreateScope()
+		assert.Nil(t, err)
+
+		cloneValue, err := GetService[*TestType](cloneScope)
+		assert.Nil(t, err)
+		assert.Same(t, shared, cloneValue)
+
+		if expire {
+			current = current.Add(2 * time.Minute)
+			cloneValue, err = GetService[*TestType](cloneScope)
+			assert.Nil(t, err)
+			assert.NotSame(t, shared, cloneValue, "expired singleton should be refreshed in the clone")
+			current = current.Add(-2 * time.Minute)
+		} else {
+			assert.Nil(t, Invalidate[*TestType](clone))
+		}
+		assert.Nil(t, cloneScope.Close())
+	}
+
+	assert.Empty(t, disposed, "the clone should not dispose the singleton of the collection")
+
+	value, err := GetService[*TestType](scope)
+	assert.Nil(t, err)
+	assert.Same(t, shared, value)
+}
+
+func TestSnapshotRestore(t *testing.T) {
+	collection := InitServiceCollection()
+
+	err := AddSingleton[TestConfig](collection, func(s *Scope) any {
+		return TestConfig{tenant: "base"}
+	})
+	assert.Nil(t, err)
+
+	snapshot := collection.Snapshot()
+
+	err = AddScoped[*TestRepository](collection, func(s *Scope) any {
+		return &TestRepository{}
+	})
+	assert.Nil(t, err)
+	assert.Nil(t, collection.Install(NewModule("repositories", func(collection *ServiceCollection) error {
+		return nil
+	})))
+
+	assert.Nil(t, collection.Restore(snapshot))
+
+	_, err = GetServiceModule[*TestRepository](collection)
+	assert.NotNil(t, err, "registrations after the snapshot should be removed")
+	_, err = GetServiceModule[TestConfig](collection)
+	assert.Nil(t, err)
+	assert.False(t, collection.installedModules["repositories"])
+
+	assert.NotNil(t, InitServiceCollection().Restore(snapshot))
+
+	collection.Lock()
+	assert.NotNil(t, collection.Restore(snapshot))
+}
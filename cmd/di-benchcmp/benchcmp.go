@@ -0,0 +1,158 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+// procsSuffix is the GOMAXPROCS suffix that go test appends to names of benchmarks.
+var procsSuffix = regexp.MustCompile(`-\d+$`)
+
+// comparison is the change of a metric of a benchmark between two outputs.
+type comparison struct {
+	// Name of the benchmark without GOMAXPROCS suffix.
+	Name string
+	// Unit of the metric, like ns/op.
+	Unit string
+	// Median of the metric in the old and new outputs.
+	Old, New float64
+	// Change of the metric in percent, it's infinite when the old value is zero.
+	Delta float64
+	// Indicates that the metric grew more than the threshold.
+	Regression bool
+}
+
+// parse reads benchmark results from an output of go test and returns the median of every metric by benchmark name.
+// Lines that aren't benchmark results are ignored.
+func parse(r io.Reader) (map[string]map[string]float64, error) {
+	samples := make(map[string]map[string][]float64)
+
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 4 || !strings.HasPrefix(fields[0], "Benchmark") {
+			continue
+		}
+		if _, err := strconv.Atoi(fields[1]); err != nil {
+			continue
+		}
+		if len(fields)%2 != 0 {
+			return nil, fmt.Errorf("line %d: metrics must be pairs of value and unit", line)
+		}
+
+		name := procsSuffix.ReplaceAllString(fields[0], "")
+		if samples[name] == nil {
+			samples[name] = make(map[string][]float64)
+		}
+
+		for i := 2; i < len(fields); i += 2 {
+			value, err := strconv.ParseFloat(fields[i], 64)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid value %q of %v", line, fields[i], fields[i+1])
+			}
+			samples[name][fields[i+1]] = append(samples[name][fields[i+1]], value)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	results := make(map[string]map[string]float64, len(samples))
+	for name, metrics := range samples {
+		results[name] = make(map[string]float64, len(metrics))
+		for unit, values := range metrics {
+			results[name][unit] = median(values)
+		}
+	}
+
+	return results, nil
+}
+
+// median returns the median of the values.
+func median(values []float64) float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	middle := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[middle-1] + sorted[middle]) / 2
+	}
+
+	return sorted[middle]
+}
+
+// compare compares the metrics of benchmarks which exist in both outputs, sorted by name and unit.
+// All metrics are considered lower-is-better, so a metric that grew more than threshold percent is a regression.
+func compare(oldResults map[string]map[string]float64, newResults map[string]map[string]float64, threshold float64) []comparison {
+	comparisons := make([]comparison, 0)
+
+	for name, oldMetrics := range oldResults {
+		newMetrics, exists := newResults[name]
+		if !exists {
+			continue
+		}
+
+		for unit, oldValue := range oldMetrics {
+			newValue, exists := newMetrics[unit]
+			if !exists {
+				continue
+			}
+
+			c := comparison{
+				Name: name,
+				Unit: unit,
+				Old:  oldValue,
+				New:  newValue,
+			}
+
+			switch {
+			case oldValue != 0:
+				c.Delta = (newValue - oldValue) / oldValue * 100
+			case newValue > 0:
+				c.Delta = math.Inf(1)
+			}
+			c.Regression = c.Delta > threshold
+
+			comparisons = append(comparisons, c)
+		}
+	}
+
+	sort.Slice(comparisons, func(i, j int) bool {
+		if comparisons[i].Name != comparisons[j].Name {
+			return comparisons[i].Name < comparisons[j].Name
+		}
+		return comparisons[i].Unit < comparisons[j].Unit
+	})
+
+	return comparisons
+}
+
+// writeComparisons writes the comparisons as a table, regressions are marked in the last column.
+func writeComparisons(w io.Writer, comparisons []comparison) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "benchmark\tunit\told\tnew\tdelta\t")
+	for _, c := range comparisons {
+		mark := ""
+		if c.Regression {
+			mark = "REGRESSION"
+		}
+		fmt.Fprintf(tw, "%v\t%v\t%v\t%v\t%+.2f%%\t%v\n",
+			c.Name, c.Unit, formatValue(c.Old), formatValue(c.New), c.Delta, mark)
+	}
+
+	return tw.Flush()
+}
+
+// formatValue formats a metric without trailing zeros.
+func formatValue(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
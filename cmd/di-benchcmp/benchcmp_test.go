@@ -0,0 +1,97 @@
+package main
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const oldOutput = `goos: linux
+goarch: amd64
+pkg: github.com/ashkanabd/go-di
+BenchmarkWarmResolution/singleton-8   	 1000000	        40 ns/op	       0 B/op	       0 allocs/op
+BenchmarkWarmResolution/singleton-8   	 1000000	        60 ns/op	       0 B/op	       0 allocs/op
+BenchmarkWarmResolution/singleton-8   	 1000000	        50 ns/op	       0 B/op	       0 allocs/op
+BenchmarkCreateScope-8                	 1000000	       100 ns/op	     192 B/op	       2 allocs/op
+BenchmarkRemoved-8                    	 1000000	       100 ns/op
+PASS
+ok  	github.com/ashkanabd/go-di	1.000s
+`
+
+const newOutput = `BenchmarkWarmResolution/singleton   	 1000000	        52 ns/op	       0 B/op	       0 allocs/op
+BenchmarkCreateScope                	 1000000	       105 ns/op	     256 B/op	       3 allocs/op
+BenchmarkAdded                      	 1000000	       100 ns/op
+`
+
+func TestParse(t *testing.T) {
+	results, err := parse(strings.NewReader(oldOutput))
+
+	assert.Nil(t, err)
+	assert.Len(t, results, 3)
+	assert.Equal(t, map[string]float64{"ns/op": 50, "B/op": 0, "allocs/op": 0}, results["BenchmarkWarmResolution/singleton"])
+	assert.Equal(t, 192.0, results["BenchmarkCreateScope"]["B/op"])
+}
+
+func TestParseInvalidValue(t *testing.T) {
+	_, err := parse(strings.NewReader("BenchmarkCreateScope 100 fast ns/op\n"))
+
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "line 1")
+}
+
+func TestMedian(t *testing.T) {
+	assert.Equal(t, 2.0, median([]float64{3, 1, 2}))
+	assert.Equal(t, 2.5, median([]float64{4, 1, 3, 2}))
+}
+
+func TestCompare(t *testing.T) {
+	oldResults, _ := parse(strings.NewReader(oldOutput))
+	newResults, _ := parse(strings.NewReader(newOutput))
+
+	comparisons := compare(oldResults, newResults, 10)
+
+	regressions := make(map[string]bool)
+	for _, c := range comparisons {
+		regressions[c.Name+" "+c.Unit] = c.Regression
+	}
+
+	assert.Len(t, comparisons, 6)
+	assert.Equal(t, map[string]bool{
+		"BenchmarkCreateScope B/op":                   true,
+		"BenchmarkCreateScope allocs/op":              true,
+		"BenchmarkCreateScope ns/op":                  false,
+		"BenchmarkWarmResolution/singleton B/op":      false,
+		"BenchmarkWarmResolution/singleton allocs/op": false,
+		"BenchmarkWarmResolution/singleton ns/op":     false,
+	}, regressions)
+}
+
+func TestCompareZeroBaseline(t *testing.T) {
+	comparisons := compare(
+		map[string]map[string]float64{"BenchmarkA": {"allocs/op": 0}},
+		map[string]map[string]float64{"BenchmarkA": {"allocs/op": 1}},
+		10,
+	)
+
+	assert.Len(t, comparisons, 1)
+	assert.True(t, math.IsInf(comparisons[0].Delta, 1))
+	assert.True(t, comparisons[0].Regression)
+}
+
+func TestWriteComparisons(t *testing.T) {
+	buffer := bytes.Buffer{}
+
+	err := writeComparisons(&buffer, []comparison{
+		{Name: "BenchmarkA", Unit: "ns/op", Old: 100, New: 120, Delta: 20, Regression: true},
+		{Name: "BenchmarkB", Unit: "ns/op", Old: 100, New: 95.5, Delta: -4.5},
+	})
+
+	assert.Nil(t, err)
+	lines := strings.Split(strings.TrimSpace(buffer.String()), "\n")
+	assert.Len(t, lines, 3)
+	assert.Equal(t, []string{"BenchmarkA", "ns/op", "100", "120", "+20.00%", "REGRESSION"}, strings.Fields(lines[1]))
+	assert.Equal(t, []string{"BenchmarkB", "ns/op", "100", "95.5", "-4.50%"}, strings.Fields(lines[2]))
+}
@@ -0,0 +1,83 @@
+// Command di-benchcmp compares two outputs of go test benchmarks and reports the benchmarks that are slower or
+// allocate more in the new output than the old one beyond a threshold, so regressions fail the build:
+//
+//	go test -run '^$' -bench . -benchmem -count 5 > new.txt
+//	go run ./cmd/di-benchcmp -threshold 10 benchmarks/baseline.txt new.txt
+//
+// Benchmarks that run more than once are compared by the median of their runs.
+// The command exits with status 1 if any regression is found.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+)
+
+func main() {
+	threshold := flag.Float64("threshold", 10, "percentage of growth of a metric which is reported as regression")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: di-benchcmp [-threshold percent] old.txt new.txt\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() != 2 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	regressions, err := run(flag.Arg(0), flag.Arg(1), *threshold)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "di-benchcmp: %v\n", err)
+		os.Exit(2)
+	}
+
+	if regressions > 0 {
+		fmt.Fprintf(os.Stderr, "di-benchcmp: %d regressions beyond %v%%\n", regressions, *threshold)
+		os.Exit(1)
+	}
+}
+
+// run compares the benchmarks of the old and new files, prints the comparison and returns the number of regressions.
+func run(oldPath string, newPath string, threshold float64) (int, error) {
+	oldResults, err := parseFile(oldPath)
+	if err != nil {
+		return 0, err
+	}
+
+	newResults, err := parseFile(newPath)
+	if err != nil {
+		return 0, err
+	}
+
+	comparisons := compare(oldResults, newResults, threshold)
+	if err = writeComparisons(os.Stdout, comparisons); err != nil {
+		return 0, err
+	}
+
+	regressions := 0
+	for _, c := range comparisons {
+		if c.Regression {
+			regressions++
+		}
+	}
+
+	return regressions, nil
+}
+
+// parseFile parses the benchmark results of a file.
+func parseFile(path string) (map[string]map[string]float64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	results, err := parse(file)
+	if err != nil {
+		return nil, fmt.Errorf("can't parse %v: %w", path, err)
+	}
+
+	return results, nil
+}
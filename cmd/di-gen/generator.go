@@ -0,0 +1,255 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/token"
+	"sort"
+	"strings"
+	"text/template"
+	"unicode"
+)
+
+// generatedService is a service in the template of generated code.
+type generatedService struct {
+	*service
+	// Name of the getter method of the service.
+	Method string
+	// Prefix of the names of the fields that keep the instance of the service.
+	Field string
+	// Getter methods of the dependencies of the service, in the order of constructor parameters.
+	Dependencies []string
+}
+
+func (s *generatedService) Type() string        { return s.typ }
+func (s *generatedService) Lifetime() string    { return s.lifetime }
+func (s *generatedService) Constructor() string { return s.constructor }
+func (s *generatedService) ReturnsError() bool  { return s.returnsError }
+
+// generate returns the formatted wiring code of the package.
+func generate(pkg *scannedPackage) ([]byte, error) {
+	services, err := resolve(pkg.services)
+	if err != nil {
+		return nil, err
+	}
+
+	imports := map[string]string{}
+	for name, importPath := range pkg.imports {
+		imports[name] = importPath
+	}
+	for _, s := range services {
+		if s.returnsError {
+			imports["fmt"] = "fmt"
+		}
+		if s.lifetime != lifetimeTransient {
+			imports["sync"] = "sync"
+		}
+	}
+
+	names := make([]string, 0, len(imports))
+	for name := range imports {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	specs := make([]string, 0, len(names))
+	for _, name := range names {
+		importPath := imports[name]
+		if strings.HasSuffix(importPath, "/"+name) || importPath == name {
+			specs = append(specs, fmt.Sprintf("%q", importPath))
+		} else {
+			specs = append(specs, fmt.Sprintf("%v %q", name, importPath))
+		}
+	}
+
+	buffer := bytes.Buffer{}
+	err = injectorTemplate.Execute(&buffer, struct {
+		Package  string
+		Imports  []string
+		Services []*generatedService
+	}{pkg.name, specs, services})
+	if err != nil {
+		return nil, err
+	}
+
+	code, err := format.Source(buffer.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("failed to format generated code: %w", err)
+	}
+
+	return code, nil
+}
+
+// resolve validates dependencies of the services and prepares them for the template.
+func resolve(services []*service) ([]*generatedService, error) {
+	byType := make(map[string]*generatedService)
+	byMethod := make(map[string]*generatedService)
+	generated := make([]*generatedService, 0, len(services))
+
+	for _, s := range services {
+		if existing, exists := byType[s.typ]; exists {
+			return nil, fmt.Errorf("%v: service %v is provided by both %v and %v", s.position, s.typ, existing.constructor, s.constructor)
+		}
+
+		method := methodName(s.typ)
+		if existing, exists := byMethod[method]; exists {
+			return nil, fmt.Errorf("%v: services %v and %v have the same getter name %v", s.position, existing.typ, s.typ, method)
+		}
+
+		g := &generatedService{
+			service: s,
+			Method:  method,
+			Field:   string(unicode.ToLower(rune(method[0]))) + method[1:],
+		}
+		byType[s.typ] = g
+		byMethod[method] = g
+		generated = append(generated, g)
+	}
+
+	for _, g := range generated {
+		for _, dependency := range g.dependencies {
+			d, exists := byType[dependency]
+			if !exists {
+				return nil, fmt.Errorf("%v: dependency %v of %v is not provided by any annotated constructor", g.position, dependency, g.typ)
+			}
+			g.Dependencies = append(g.Dependencies, d.Method)
+		}
+	}
+
+	for _, g := range generated {
+		if err := checkDependencies(g, byType, []string{g.typ}); err != nil {
+			return nil, fmt.Errorf("%v: %w", g.position, err)
+		}
+	}
+
+	return generated, nil
+}
+
+// checkDependencies reports circular dependencies and scoped services that a singleton depends on,
+// directly or through transient services.
+func checkDependencies(root *generatedService, byType map[string]*generatedService, path []string) error {
+	current := byType[path[len(path)-1]]
+
+	for _, dependency := range current.dependencies {
+		for _, visited := range path {
+			if visited == dependency {
+				return fmt.Errorf("circular dependency %v", strings.Join(append(path, dependency), " -> "))
+			}
+		}
+
+		d := byType[dependency]
+		if root.lifetime == lifetimeSingleton && d.lifetime == lifetimeScoped {
+			return fmt.Errorf("singleton %v can't depend on scoped %v", root.typ, d.typ)
+		}
+
+		if err := checkDependencies(root, byType, append(path, dependency)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// methodName returns the name of the getter method of a service type, e.g. UserService for *users.UserService.
+func methodName(typ string) string {
+	name := strings.TrimLeft(typ, "*[]")
+	if index := strings.LastIndex(name, "."); index >= 0 {
+		name = name[index+1:]
+	}
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' {
+			return r
+		}
+		return -1
+	}, name)
+
+	if name == "" {
+		name = "Service"
+	}
+	name = string(unicode.ToUpper(rune(name[0]))) + name[1:]
+	if !token.IsExported(name) {
+		name = "Service" + name
+	}
+
+	return name
+}
+
+// injectorTemplate is the template of the generated code.
+var injectorTemplate = template.Must(template.New("injector").Parse(`// Code generated by di-gen. DO NOT EDIT.
+
+package {{.Package}}
+
+{{if .Imports}}import (
+{{range .Imports}}	{{.}}
+{{end}})
+{{end}}
+
+// Injector provides the services of the package without reflection, singletons are shared by all of its scopes.
+type Injector struct {
+{{- range .Services}}{{if eq .Lifetime "singleton"}}
+	{{.Field}}Mutex sync.Mutex
+	{{.Field}}Built bool
+	{{.Field}}Value {{.Type}}
+{{- end}}{{end}}
+}
+
+// NewInjector initialize an Injector.
+func NewInjector() *Injector {
+	return &Injector{}
+}
+
+// InjectorScope provides the services of an Injector, scoped services are shared in the scope.
+type InjectorScope struct {
+	injector *Injector
+{{- range .Services}}{{if eq .Lifetime "scoped"}}
+	{{.Field}}Mutex sync.Mutex
+	{{.Field}}Built bool
+	{{.Field}}Value {{.Type}}
+{{- end}}{{end}}
+}
+
+// CreateScope creates a new scope of the injector.
+func (injector *Injector) CreateScope() *InjectorScope {
+	return &InjectorScope{
+		injector: injector,
+	}
+}
+{{range .Services}}
+// {{.Method}} returns the {{.Lifetime}} service {{.Type}}.
+func (scope *InjectorScope) {{.Method}}() ({{.Type}}, error) {
+{{- if ne .Lifetime "transient"}}
+{{- if eq .Lifetime "singleton"}}
+	owner := scope.injector
+{{- else}}
+	owner := scope
+{{- end}}
+	owner.{{.Field}}Mutex.Lock()
+	defer owner.{{.Field}}Mutex.Unlock()
+
+	if owner.{{.Field}}Built {
+		return owner.{{.Field}}Value, nil
+	}
+{{end}}
+{{- if or .Dependencies .ReturnsError}}
+	var zero {{.Type}}
+{{end}}
+{{- range $i, $dependency := .Dependencies}}
+	dependency{{$i}}, err := scope.{{$dependency}}()
+	if err != nil {
+		return zero, err
+	}
+{{end}}
+	{{if .ReturnsError}}value, err := {{else}}value := {{end}}{{.Constructor}}({{range $i, $dependency := .Dependencies}}{{if $i}}, {{end}}dependency{{$i}}{{end}})
+{{- if .ReturnsError}}
+	if err != nil {
+		return zero, fmt.Errorf("failed to initialize service {{.Type}}: %w", err)
+	}
+{{- end}}
+{{if ne .Lifetime "transient"}}
+	owner.{{.Field}}Value = value
+	owner.{{.Field}}Built = true
+{{end}}
+	return value, nil
+}
+{{end}}`))
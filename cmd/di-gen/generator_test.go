@@ -0,0 +1,219 @@
+package main
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testSource = `package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+type Config struct {
+	name string
+}
+
+//di:singleton
+func NewConfig() *Config {
+	return &Config{name: "app"}
+}
+
+type Repository interface {
+	Name() string
+}
+
+type MemoryRepository struct {
+	config *Config
+}
+
+func (repository *MemoryRepository) Name() string {
+	return repository.config.name
+}
+
+var repositories = 0
+
+//di:scoped Repository
+func NewMemoryRepository(config *Config) (*MemoryRepository, error) {
+	repositories++
+	if repositories > 2 {
+		return nil, errors.New("too many repositories")
+	}
+	return &MemoryRepository{config: config}, nil
+}
+
+type Handler struct {
+	repository Repository
+	builder    *strings.Builder
+}
+
+//di:transient
+func NewHandler(repository Repository, builder *strings.Builder) Handler {
+	return Handler{repository: repository, builder: builder}
+}
+
+//di:transient
+func NewBuilder() *strings.Builder {
+	return &strings.Builder{}
+}
+
+func main() {
+	injector := NewInjector()
+
+	scope := injector.CreateScope()
+	first, _ := scope.Handler()
+	second, _ := scope.Handler()
+	config, _ := scope.Config()
+	other, _ := injector.CreateScope().Config()
+
+	_, err := injector.CreateScope().Repository()
+	_, failed := injector.CreateScope().Repository()
+
+	fmt.Println(first.repository.Name(), first.repository == second.repository, first.builder != second.builder, config == other, err, failed)
+}
+`
+
+func writePackage(t *testing.T, source string) string {
+	dir := t.TempDir()
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example\n\ngo 1.18\n"), 0o644))
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "main.go"), []byte(source), 0o644))
+
+	return dir
+}
+
+func TestGenerate(t *testing.T) {
+	dir := writePackage(t, testSource)
+
+	assert.Nil(t, run(dir, "di_gen.go"))
+
+	code, err := os.ReadFile(filepath.Join(dir, "di_gen.go"))
+	assert.Nil(t, err)
+	assert.True(t, strings.HasPrefix(string(code), "// Code generated by di-gen. DO NOT EDIT."))
+	assert.Contains(t, string(code), "func (scope *InjectorScope) Repository() (Repository, error)")
+	assert.Contains(t, string(code), `"strings"`)
+
+	// Generating again ignores the generated file.
+	assert.Nil(t, run(dir, "di_gen.go"))
+
+	goBinary, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go command is not available to compile generated code")
+	}
+
+	cmd := exec.Command(goBinary, "run", ".")
+	cmd.Dir = dir
+	output, err := cmd.CombinedOutput()
+	assert.Nil(t, err, string(output))
+	assert.Equal(t, "app true true true <nil> failed to initialize service Repository: too many repositories\n", string(output))
+}
+
+func TestGenerateErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		err    string
+	}{
+		{
+			name: "missing dependency",
+			source: `package main
+
+type Config struct{}
+type Repository struct{}
+
+//di:scoped
+func NewRepository(config *Config) *Repository { return &Repository{} }
+`,
+			err: "dependency *Config of *Repository is not provided by any annotated constructor",
+		},
+		{
+			name: "circular dependency",
+			source: `package main
+
+type A struct{}
+type B struct{}
+
+//di:scoped
+func NewA(b *B) *A { return &A{} }
+
+//di:transient
+func NewB(a *A) *B { return &B{} }
+`,
+			err: "circular dependency *A -> *B -> *A",
+		},
+		{
+			name: "captive dependency",
+			source: `package main
+
+type Session struct{}
+type Cache struct{}
+
+//di:scoped
+func NewSession() *Session { return &Session{} }
+
+//di:singleton
+func NewCache(session *Session) *Cache { return &Cache{} }
+`,
+			err: "singleton *Cache can't depend on scoped *Session",
+		},
+		{
+			name: "duplicate service",
+			source: `package main
+
+type Config struct{}
+
+//di:singleton
+func NewConfig() *Config { return &Config{} }
+
+//di:singleton
+func NewDefaultConfig() *Config { return &Config{} }
+`,
+			err: "service *Config is provided by both NewConfig and NewDefaultConfig",
+		},
+		{
+			name: "invalid lifetime",
+			source: `package main
+
+type Config struct{}
+
+//di:forever
+func NewConfig() *Config { return &Config{} }
+`,
+			err: "invalid lifetime forever of NewConfig",
+		},
+		{
+			name: "invalid results",
+			source: `package main
+
+type Config struct{}
+
+//di:singleton
+func NewConfig() (*Config, bool) { return &Config{}, true }
+`,
+			err: "constructor NewConfig must return a service and an optional error",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := run(writePackage(t, test.source), "di_gen.go")
+			assert.NotNil(t, err)
+			if err != nil {
+				assert.Contains(t, err.Error(), test.err)
+			}
+		})
+	}
+}
+
+func TestMethodName(t *testing.T) {
+	assert.Equal(t, "UserService", methodName("*UserService"))
+	assert.Equal(t, "DB", methodName("*sql.DB"))
+	assert.Equal(t, "Config", methodName("config"))
+	assert.Equal(t, "Handlers", methodName("[]Handlers"))
+}
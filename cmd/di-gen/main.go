@@ -0,0 +1,47 @@
+// Command di-gen generates compile-time wiring code for the constructors of a package which are annotated with their
+// lifetime, so missing dependencies are reported before running the application and resolutions use no reflection.
+//
+// Constructors are annotated with a //di:singleton, //di:scoped or //di:transient comment, optionally followed by
+// the type that the service is provided as, e.g. an interface that the returned value implements:
+//
+//	//di:scoped Repository
+//	func NewSQLRepository(db *sql.DB) *SQLRepository
+//
+// Parameters of constructors are their dependencies and constructors can return an error as their second result.
+// The generated Injector holds singletons and its scopes provide all services with typed getter methods:
+//
+//	//go:generate go run github.com/ashkanabd/go-di/cmd/di-gen
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+func main() {
+	dir := flag.String("dir", ".", "directory of the package to scan")
+	output := flag.String("output", "di_gen.go", "name of the generated file in the package directory")
+	flag.Parse()
+
+	if err := run(*dir, *output); err != nil {
+		fmt.Fprintf(os.Stderr, "di-gen: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// run generates the wiring code of the package in dir.
+func run(dir string, output string) error {
+	pkg, err := parsePackage(dir, output)
+	if err != nil {
+		return err
+	}
+
+	code, err := generate(pkg)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(dir, output), code, 0o644)
+}
@@ -0,0 +1,243 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Lifetimes of annotated constructors.
+const (
+	lifetimeSingleton = "singleton"
+	lifetimeScoped    = "scoped"
+	lifetimeTransient = "transient"
+)
+
+// annotationPrefix is the prefix of comments that annotate constructors.
+const annotationPrefix = "//di:"
+
+// scannedPackage is a package with annotated constructors.
+type scannedPackage struct {
+	// Name of the package.
+	name string
+	// Import paths of the packages that types of services and dependencies refer to, by their names.
+	imports map[string]string
+	// Services of the annotated constructors.
+	services []*service
+}
+
+// service is a service which is provided by an annotated constructor.
+type service struct {
+	// Type that the service is provided as.
+	typ string
+	// Lifetime of the service.
+	lifetime string
+	// Name of the constructor.
+	constructor string
+	// Types of the parameters of the constructor.
+	dependencies []string
+	// Indicates that the constructor returns an error as the second result.
+	returnsError bool
+	// Position of the constructor in source code.
+	position token.Position
+}
+
+// parsePackage finds annotated constructors in non-test files of the package in dir, except the output file.
+func parsePackage(dir string, output string) (*scannedPackage, error) {
+	fset := token.NewFileSet()
+	packages, err := parser.ParseDir(fset, dir, func(info os.FileInfo) bool {
+		return !strings.HasSuffix(info.Name(), "_test.go") && info.Name() != output
+	}, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(packages) != 1 {
+		return nil, fmt.Errorf("expected one package in %v, found %v", dir, len(packages))
+	}
+
+	pkg := &scannedPackage{
+		imports:  make(map[string]string),
+		services: make([]*service, 0),
+	}
+
+	for name, p := range packages {
+		pkg.name = name
+
+		files := make([]string, 0, len(p.Files))
+		for filename := range p.Files {
+			files = append(files, filename)
+		}
+		sort.Strings(files)
+
+		for _, filename := range files {
+			if err := pkg.scanFile(fset, p.Files[filename]); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return pkg, nil
+}
+
+// scanFile finds annotated constructors of a file.
+func (pkg *scannedPackage) scanFile(fset *token.FileSet, file *ast.File) error {
+	fileImports := make(map[string]string)
+	for _, spec := range file.Imports {
+		importPath, _ := strconv.Unquote(spec.Path.Value)
+		name := path.Base(importPath)
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+		fileImports[name] = importPath
+	}
+
+	for _, decl := range file.Decls {
+		function, ok := decl.(*ast.FuncDecl)
+		if !ok || function.Doc == nil || function.Recv != nil {
+			continue
+		}
+
+		for _, comment := range function.Doc.List {
+			if !strings.HasPrefix(comment.Text, annotationPrefix) {
+				continue
+			}
+
+			s, err := parseConstructor(fset, function, strings.Fields(strings.TrimPrefix(comment.Text, annotationPrefix)))
+			if err != nil {
+				return fmt.Errorf("%v: %w", fset.Position(function.Pos()), err)
+			}
+
+			for _, field := range append(function.Type.Params.List, function.Type.Results.List...) {
+				if err := pkg.addImports(field.Type, fileImports); err != nil {
+					return fmt.Errorf("%v: %w", s.position, err)
+				}
+			}
+			if typ, err := parser.ParseExpr(s.typ); err == nil {
+				if err := pkg.addImports(typ, fileImports); err != nil {
+					return fmt.Errorf("%v: %w", s.position, err)
+				}
+			}
+			pkg.services = append(pkg.services, s)
+		}
+	}
+
+	return nil
+}
+
+// parseConstructor returns the service of a constructor with given annotation.
+func parseConstructor(fset *token.FileSet, function *ast.FuncDecl, annotation []string) (*service, error) {
+	if len(annotation) == 0 || len(annotation) > 2 {
+		return nil, fmt.Errorf("invalid annotation of %v, expected //di:<lifetime> [type]", function.Name.Name)
+	}
+
+	s := &service{
+		lifetime:     annotation[0],
+		constructor:  function.Name.Name,
+		dependencies: make([]string, 0),
+		position:     fset.Position(function.Pos()),
+	}
+
+	if s.lifetime != lifetimeSingleton && s.lifetime != lifetimeScoped && s.lifetime != lifetimeTransient {
+		return nil, fmt.Errorf("invalid lifetime %v of %v", s.lifetime, s.constructor)
+	}
+
+	if function.Type.TypeParams != nil {
+		return nil, fmt.Errorf("constructor %v can't have type parameters", s.constructor)
+	}
+
+	results := expandFields(fset, function.Type.Results)
+	switch {
+	case len(results) == 1:
+	case len(results) == 2 && results[1] == "error":
+		s.returnsError = true
+	default:
+		return nil, fmt.Errorf("constructor %v must return a service and an optional error", s.constructor)
+	}
+
+	s.typ = results[0]
+	if len(annotation) == 2 {
+		typ, err := parser.ParseExpr(annotation[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid type %v of %v", annotation[1], s.constructor)
+		}
+		s.typ = formatExpr(token.NewFileSet(), typ)
+	}
+
+	for _, param := range function.Type.Params.List {
+		if _, variadic := param.Type.(*ast.Ellipsis); variadic {
+			return nil, fmt.Errorf("constructor %v can't have variadic parameters", s.constructor)
+		}
+	}
+	s.dependencies = expandFields(fset, function.Type.Params)
+
+	return s, nil
+}
+
+// expandFields returns the type of every field of a field list, including the fields which share their type.
+func expandFields(fset *token.FileSet, fields *ast.FieldList) []string {
+	types := make([]string, 0)
+	if fields == nil {
+		return types
+	}
+
+	for _, field := range fields.List {
+		typ := formatExpr(fset, field.Type)
+
+		count := len(field.Names)
+		if count == 0 {
+			count = 1
+		}
+		for i := 0; i < count; i++ {
+			types = append(types, typ)
+		}
+	}
+
+	return types
+}
+
+// addImports records the imports that a type expression refers to.
+func (pkg *scannedPackage) addImports(expr ast.Expr, fileImports map[string]string) error {
+	var err error
+	ast.Inspect(expr, func(node ast.Node) bool {
+		selector, ok := node.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+
+		ident, ok := selector.X.(*ast.Ident)
+		if !ok {
+			return true
+		}
+
+		importPath, exists := fileImports[ident.Name]
+		if !exists {
+			return true
+		}
+
+		if recorded, exists := pkg.imports[ident.Name]; exists && recorded != importPath {
+			err = fmt.Errorf("package name %v refers to both %v and %v", ident.Name, recorded, importPath)
+		}
+		pkg.imports[ident.Name] = importPath
+
+		return true
+	})
+
+	return err
+}
+
+// formatExpr returns the source code of an expression.
+func formatExpr(fset *token.FileSet, expr ast.Expr) string {
+	buffer := bytes.Buffer{}
+	_ = printer.Fprint(&buffer, fset, expr)
+
+	return buffer.String()
+}
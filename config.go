@@ -0,0 +1,155 @@
+package dependency_injection
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ConfigSource is a source of configuration values which are bound to options by AddOptions.
+type ConfigSource interface {
+	// Load returns configuration values by their keys, keys are lowercase names of sections and fields
+	// separated by dots, like "database.host".
+	Load() (map[string]any, error)
+}
+
+// configSourceFunc is a ConfigSource which is defined by a function.
+type configSourceFunc func() (map[string]any, error)
+
+// Load returns the values of the function.
+func (f configSourceFunc) Load() (map[string]any, error) {
+	return f()
+}
+
+// MapSource returns a source of given values, nested maps are sections. It's useful for defaults of options
+// which are computed in code.
+func MapSource(values map[string]any) ConfigSource {
+	return configSourceFunc(func() (map[string]any, error) {
+		flattened := make(map[string]any)
+		flattenConfig("", values, flattened)
+		return flattened, nil
+	})
+}
+
+// JSONFileSource returns a source of the values of a JSON file, objects of the file are sections.
+func JSONFileSource(path string) ConfigSource {
+	return configSourceFunc(func() (map[string]any, error) {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("can't load configuration file %v: %w", path, err)
+		}
+
+		var values map[string]any
+		decoder := json.NewDecoder(bytes.NewReader(content))
+		decoder.UseNumber()
+		if err = decoder.Decode(&values); err != nil {
+			return nil, fmt.Errorf("can't parse configuration file %v: %w", path, err)
+		}
+
+		flattened := make(map[string]any)
+		flattenConfig("", values, flattened)
+		return flattened, nil
+	})
+}
+
+// EnvSource returns a source of the environment variables which start with given prefix and an underscore.
+// Sections are separated by double underscores after the prefix, so APP_DATABASE__HOST is the value of
+// database.host key for APP prefix.
+func EnvSource(prefix string) ConfigSource {
+	return configSourceFunc(func() (map[string]any, error) {
+		values := make(map[string]any)
+
+		for _, env := range os.Environ() {
+			name, value, _ := strings.Cut(env, "=")
+			if !strings.HasPrefix(name, prefix+"_") {
+				continue
+			}
+
+			key := strings.ReplaceAll(strings.TrimPrefix(name, prefix+"_"), "__", ".")
+			values[strings.ToLower(key)] = value
+		}
+
+		return values, nil
+	})
+}
+
+// FlagSource returns a source of the flags of given flag set which are set in the command line,
+// names of flags are keys like "database.host". The flag set must be parsed before resolving options.
+func FlagSource(flags *flag.FlagSet) ConfigSource {
+	return configSourceFunc(func() (map[string]any, error) {
+		values := make(map[string]any)
+
+		flags.Visit(func(f *flag.Flag) {
+			values[strings.ToLower(f.Name)] = f.Value.String()
+		})
+
+		return values, nil
+	})
+}
+
+// flattenConfig adds the values of nested maps to flattened by their keys.
+func flattenConfig(prefix string, value any, flattened map[string]any) {
+	switch value := value.(type) {
+	case map[string]any:
+		for key, nested := range value {
+			flattenConfig(joinConfigKey(prefix, key), nested, flattened)
+		}
+	case map[any]any:
+		for key, nested := range value {
+			flattenConfig(joinConfigKey(prefix, fmt.Sprint(key)), nested, flattened)
+		}
+	default:
+		flattened[prefix] = value
+	}
+}
+
+// joinConfigKey returns the key of a name in a section.
+func joinConfigKey(section string, name string) string {
+	if section == "" {
+		return strings.ToLower(name)
+	}
+
+	return section + "." + strings.ToLower(name)
+}
+
+// AddConfigSource adds sources of configuration which options are bound from, values of sources which are added later
+// override values of former ones. Options which are registered in a child collection are bound from the sources of
+// its parents and then its own sources, but options which are inherited from a parent collection are bound only from
+// the sources of the parent, see AddOptions.
+func (collection *ServiceCollection) AddConfigSource(sources ...ConfigSource) error {
+	if err := collection.checkLock(); err != nil {
+		return err
+	}
+
+	collection.configSources = append(collection.configSources, sources...)
+
+	return nil
+}
+
+// loadConfig loads configuration values of the sources of the collection and its parents.
+func (collection *ServiceCollection) loadConfig() (map[string]any, error) {
+	values := make(map[string]any)
+
+	if collection.parent != nil {
+		parentValues, err := collection.parent.loadConfig()
+		if err != nil {
+			return nil, err
+		}
+		values = parentValues
+	}
+
+	for _, source := range collection.configSources {
+		sourceValues, err := source.Load()
+		if err != nil {
+			return nil, err
+		}
+		for key, value := range sourceValues {
+			values[key] = value
+		}
+	}
+
+	return values, nil
+}
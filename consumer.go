@@ -0,0 +1,158 @@
+package dependency_injection
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// Transactional is implemented by scoped services that take part in the unit of work of RunInScope,
+// like database transactions. They are committed if the unit of work succeeds and rolled back otherwise.
+type Transactional interface {
+	// Commit applies the changes of the unit of work.
+	Commit() error
+	// Rollback discards the changes of the unit of work.
+	Rollback() error
+}
+
+// RunInScope runs fn as a unit of work in a new scope of the collection which is created with ctx.
+// The context that is passed to fn carries the scope. When fn returns, scoped services of the scope that implement
+// Transactional are committed in the order they are initialized, or rolled back in reverse order if fn returned
+// an error, panicked or a commit failed. The scope is always closed.
+func RunInScope(ctx context.Context, collection *ServiceCollection, fn func(ctx context.Context, s *Scope) error) (err error) {
+	scope, err := collection.CreateScopeWithContext(ctx)
+	if err != nil {
+		return err
+	}
+	defer scope.Close()
+
+	completed := false
+	defer func() {
+		if !completed {
+			_ = rollback(scope.transactionalServices())
+		}
+	}()
+
+	err = fn(ContextWithScope(ctx, scope), scope)
+	completed = true
+
+	transactional := scope.transactionalServices()
+	if err != nil {
+		if rollbackErr := rollback(transactional); rollbackErr != nil {
+			return fmt.Errorf("%w, %v", err, rollbackErr)
+		}
+		return err
+	}
+
+	for i, service := range transactional {
+		if err := service.Commit(); err != nil {
+			err = fmt.Errorf("failed to commit %T: %w", service, err)
+			// The service which failed to commit may still have an open transaction, so it's rolled back too.
+			if rollbackErr := rollback(transactional[i:]); rollbackErr != nil {
+				return fmt.Errorf("%w, %v", err, rollbackErr)
+			}
+			return err
+		}
+	}
+
+	return nil
+}
+
+// transactionalServices returns initialized scoped services of the scope that implement Transactional.
+func (s *Scope) transactionalServices() []Transactional {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	transactional := make([]Transactional, 0)
+	if !s.current() {
+		// The scope is closed by fn, so its state may belong to an other scope.
+		return transactional
+	}
+	for _, service := range s.scopedServices {
+		if t, ok := service.(Transactional); ok {
+			transactional = append(transactional, t)
+		}
+	}
+
+	return transactional
+}
+
+// rollback rolls back given services in reverse order and returns the first error.
+func rollback(services []Transactional) error {
+	var err error
+	for i := len(services) - 1; i >= 0; i-- {
+		if rollbackErr := services[i].Rollback(); rollbackErr != nil && err == nil {
+			err = fmt.Errorf("failed to rollback %T: %w", services[i], rollbackErr)
+		}
+	}
+
+	return err
+}
+
+// WorkerPool processes messages of a channel concurrently, each message is processed by RunInScope in its own scope.
+// It's independent of the transport, so any message queue consumer can feed the channel.
+type WorkerPool[M any] struct {
+	collection   *ServiceCollection
+	workers      int
+	handler      func(ctx context.Context, s *Scope, message M) error
+	errorHandler func(message M, err error)
+}
+
+// NewWorkerPool initialize a WorkerPool that processes messages with given number of workers and handler.
+func NewWorkerPool[M any](collection *ServiceCollection, workers int, handler func(ctx context.Context, s *Scope, message M) error) *WorkerPool[M] {
+	if workers < 1 {
+		workers = 1
+	}
+
+	return &WorkerPool[M]{
+		collection: collection,
+		workers:    workers,
+		handler:    handler,
+	}
+}
+
+// OnError sets a function that is called for every message that failed, e.g. to nack it in the message queue.
+func (pool *WorkerPool[M]) OnError(errorHandler func(message M, err error)) *WorkerPool[M] {
+	pool.errorHandler = errorHandler
+	return pool
+}
+
+// Run processes messages until the channel is closed or ctx is done and waits for messages in progress.
+// It returns the error of ctx if it's done before the channel is closed.
+func (pool *WorkerPool[M]) Run(ctx context.Context, messages <-chan M) error {
+	wg := sync.WaitGroup{}
+	wg.Add(pool.workers)
+
+	for i := 0; i < pool.workers; i++ {
+		go func() {
+			defer wg.Done()
+
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case message, ok := <-messages:
+					if !ok {
+						return
+					}
+					pool.process(ctx, message)
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	return ctx.Err()
+}
+
+// process runs the handler for a message in its own scope.
+func (pool *WorkerPool[M]) process(ctx context.Context, message M) {
+	err := RunInScope(ctx, pool.collection, func(ctx context.Context, s *Scope) error {
+		return pool.handler(ctx, s, message)
+	})
+
+	if err != nil && pool.errorHandler != nil {
+		pool.errorHandler(message, err)
+	}
+}
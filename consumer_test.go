@@ -0,0 +1,187 @@
+package dependency_injection
+
+import (
+	"context"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+type TestTransaction struct {
+	name      string
+	log       *[]string
+	commitErr error
+}
+
+func (transaction *TestTransaction) Commit() error {
+	*transaction.log = append(*transaction.log, "commit "+transaction.name)
+	return transaction.commitErr
+}
+
+func (transaction *TestTransaction) Rollback() error {
+	*transaction.log = append(*transaction.log, "rollback "+transaction.name)
+	return nil
+}
+
+type TestOutbox struct {
+	*TestTransaction
+}
+
+func initTransactionalCollection(t *testing.T, log *[]string, commitErr error) *ServiceCollection {
+	collection := InitServiceCollection()
+
+	err := AddScoped[*TestTransaction](collection, func(s *Scope) any {
+		return &TestTransaction{name: "transaction", log: log, commitErr: commitErr}
+	})
+	assert.Nil(t, err)
+	err = AddScoped[*TestOutbox](collection, func(s *Scope) any {
+		_, _ = GetService[*TestTransaction](s)
+		return &TestOutbox{&TestTransaction{name: "outbox", log: log}}
+	})
+	assert.Nil(t, err)
+
+	collection.Lock()
+
+	return collection
+}
+
+func TestRunInScopeCommit(t *testing.T) {
+	log := make([]string, 0)
+	collection := initTransactionalCollection(t, &log, nil)
+
+	var runScope *Scope
+	err := RunInScope(context.Background(), collection, func(ctx context.Context, s *Scope) error {
+		contextScope, err := ScopeFromContext(ctx)
+		assert.Nil(t, err)
+		assert.Equal(t, s, contextScope)
+		runScope = s
+
+		_, err = GetService[*TestOutbox](s)
+		return err
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"commit transaction", "commit outbox"}, log)
+	assert.NotNil(t, runScope.checkClosed(), "scope should be closed")
+	assert.Equal(t, int64(0), collection.Metrics().LiveScopes)
+}
+
+func TestRunInScopeRollback(t *testing.T) {
+	log := make([]string, 0)
+	collection := initTransactionalCollection(t, &log, nil)
+
+	err := RunInScope(context.Background(), collection, func(ctx context.Context, s *Scope) error {
+		_, _ = GetService[*TestOutbox](s)
+		return fmt.Errorf("handler failed")
+	})
+
+	assert.EqualError(t, err, "handler failed")
+	assert.Equal(t, []string{"rollback outbox", "rollback transaction"}, log)
+	assert.Equal(t, int64(0), collection.Metrics().LiveScopes)
+}
+
+func TestRunInScopeCommitFailure(t *testing.T) {
+	log := make([]string, 0)
+	collection := initTransactionalCollection(t, &log, fmt.Errorf("conflict"))
+
+	err := RunInScope(context.Background(), collection, func(ctx context.Context, s *Scope) error {
+		_, err := GetService[*TestOutbox](s)
+		return err
+	})
+
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "conflict")
+	assert.Equal(t, []string{"commit transaction", "rollback outbox", "rollback transaction"}, log)
+}
+
+func TestRunInScopePanic(t *testing.T) {
+	log := make([]string, 0)
+	collection := initTransactionalCollection(t, &log, nil)
+
+	assert.Panics(t, func() {
+		_ = RunInScope(context.Background(), collection, func(ctx context.Context, s *Scope) error {
+			_, _ = GetService[*TestTransaction](s)
+			panic("handler panicked")
+		})
+	})
+
+	assert.Equal(t, []string{"rollback transaction"}, log)
+	assert.Equal(t, int64(0), collection.Metrics().LiveScopes)
+}
+
+func TestRunInScopeWithoutLock(t *testing.T) {
+	collection := InitServiceCollection()
+
+	err := RunInScope(context.Background(), collection, func(ctx context.Context, s *Scope) error {
+		t.Fatal("function should not be called")
+		return nil
+	})
+	assert.NotNil(t, err)
+}
+
+func TestWorkerPool(t *testing.T) {
+	collection := InitServiceCollection()
+
+	err := AddScoped[*RequestInfo](collection, func(s *Scope) any {
+		return &RequestInfo{}
+	})
+	assert.Nil(t, err)
+
+	collection.Lock()
+
+	processed := int64(0)
+	scopes := sync.Map{}
+	failed := make([]int, 0)
+	mutex := sync.Mutex{}
+
+	pool := NewWorkerPool[int](collection, 4, func(ctx context.Context, s *Scope, message int) error {
+		info, err := GetService[*RequestInfo](s)
+		assert.Nil(t, err)
+		assert.Equal(t, "", info.requestID, "scoped service should not be shared between messages")
+		info.requestID = fmt.Sprint(message)
+
+		_, loaded := scopes.LoadOrStore(s.ID(), true)
+		assert.False(t, loaded, "each message should have its own scope")
+
+		atomic.AddInt64(&processed, 1)
+		if message%10 == 0 {
+			return fmt.Errorf("message %v failed", message)
+		}
+		return nil
+	}).OnError(func(message int, err error) {
+		mutex.Lock()
+		failed = append(failed, message)
+		mutex.Unlock()
+	})
+
+	messages := make(chan int)
+	go func() {
+		for i := 1; i <= 100; i++ {
+			messages <- i
+		}
+		close(messages)
+	}()
+
+	err = pool.Run(context.Background(), messages)
+
+	assert.Nil(t, err)
+	assert.Equal(t, int64(100), processed)
+	assert.ElementsMatch(t, []int{10, 20, 30, 40, 50, 60, 70, 80, 90, 100}, failed)
+	assert.Equal(t, int64(0), collection.Metrics().LiveScopes)
+}
+
+func TestWorkerPoolCancel(t *testing.T) {
+	collection := InitServiceCollection()
+	collection.Lock()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := NewWorkerPool[int](collection, 2, func(ctx context.Context, s *Scope, message int) error {
+		return nil
+	}).Run(ctx, make(chan int))
+
+	assert.Equal(t, context.Canceled, err)
+}
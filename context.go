@@ -0,0 +1,24 @@
+package dependency_injection
+
+import (
+	"context"
+	"fmt"
+)
+
+// scopeContextKey is the key of the scope in contexts.
+type scopeContextKey struct{}
+
+// ContextWithScope returns a copy of ctx that carries the scope.
+func ContextWithScope(ctx context.Context, s *Scope) context.Context {
+	return context.WithValue(ctx, scopeContextKey{}, s)
+}
+
+// ScopeFromContext returns the scope that ctx carries.
+func ScopeFromContext(ctx context.Context) (*Scope, error) {
+	s, ok := ctx.Value(scopeContextKey{}).(*Scope)
+	if !ok || s == nil {
+		return nil, fmt.Errorf("there is no scope in context")
+	}
+
+	return s, nil
+}
@@ -0,0 +1,58 @@
+package dependency_injection
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+type requestIDKey struct{}
+
+type RequestInfo struct {
+	requestID string
+}
+
+func TestCreateScopeWithContext(t *testing.T) {
+	collection := InitServiceCollection()
+
+	err := AddScoped[*RequestInfo](collection, func(s *Scope) any {
+		requestID, _ := s.Context().Value(requestIDKey{}).(string)
+		return &RequestInfo{requestID: requestID}
+	})
+	assert.Nil(t, err)
+
+	collection.Lock()
+
+	scope, err := collection.CreateScopeWithContext(context.WithValue(context.Background(), requestIDKey{}, "abc"))
+	assert.Nil(t, err)
+	defer scope.Close()
+
+	info, err := GetService[*RequestInfo](scope)
+	assert.Nil(t, err)
+	assert.Equal(t, "abc", info.requestID)
+
+	emptyScope, err := collection.CreateScope()
+	assert.Nil(t, err)
+	defer emptyScope.Close()
+
+	assert.Equal(t, context.Background(), emptyScope.Context())
+	info, err = GetService[*RequestInfo](emptyScope)
+	assert.Nil(t, err)
+	assert.Equal(t, "", info.requestID)
+}
+
+func TestScopeFromContext(t *testing.T) {
+	collection := InitServiceCollection()
+	collection.Lock()
+
+	scope, err := collection.CreateScope()
+	assert.Nil(t, err)
+	defer scope.Close()
+
+	_, err = ScopeFromContext(context.Background())
+	assert.NotNil(t, err)
+
+	contextScope, err := ScopeFromContext(ContextWithScope(context.Background(), scope))
+	assert.Nil(t, err)
+	assert.Equal(t, scope, contextScope)
+}
@@ -0,0 +1,240 @@
+package dependency_injection
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// DebugInfo is the state of a ServiceCollection which is shown by the debug handler.
+type DebugInfo struct {
+	// Services that can be requested from the collection, sorted by type.
+	Registrations []DebugRegistration `json:"registrations"`
+	// Indicates that active scopes are tracked, see EnableScopeTracking.
+	ScopeTracking bool `json:"scopeTracking"`
+	// Active scopes of the collection, sorted by ID.
+	Scopes []DebugScope `json:"scopes"`
+	// Dependency graph of the collection.
+	Graph *Graph `json:"graph"`
+}
+
+// DebugRegistration is a registered service in DebugInfo.
+type DebugRegistration struct {
+	// Type that the service is registered with.
+	Type string `json:"type"`
+	// Lifetime of the service.
+	Lifetime string `json:"lifetime"`
+	// Name of the module that registered the service.
+	Module string `json:"module,omitempty"`
+	// Indicates that the service is inherited from a parent collection.
+	Inherited bool `json:"inherited,omitempty"`
+	// Indicates that the singleton service is initialized, always false for other lifetimes.
+	Built bool `json:"built"`
+	// Statistics of the object pool of a pooled service.
+	Pool *PoolStats `json:"pool,omitempty"`
+}
+
+// DebugScope is an active scope in DebugInfo.
+type DebugScope struct {
+	// ID of the scope.
+	ID uint64 `json:"id"`
+	// Instances of scoped and pooled services which are cached in the scope.
+	Instances []DebugInstance `json:"instances"`
+}
+
+// DebugInstance is a cached instance of a service in a scope.
+type DebugInstance struct {
+	// Type that the service is registered with.
+	Service string `json:"service"`
+	// Concrete type of the cached instance.
+	Type string `json:"type"`
+}
+
+// EnableScopeTracking keeps track of the scopes which are created and not closed yet, so they can be inspected in
+// the debug handler. It can't be enabled after locking the collection. Tracked scopes are kept in memory until
+// they are closed, so all scopes must be closed when tracking is enabled.
+func (collection *ServiceCollection) EnableScopeTracking() error {
+	if err := collection.checkLock(); err != nil {
+		return err
+	}
+
+	if collection.activeScopes == nil {
+		collection.activeScopes = make(map[uint64]*Scope)
+	}
+
+	return nil
+}
+
+// trackScope adds the scope to active scopes if scope tracking is enabled.
+func (collection *ServiceCollection) trackScope(s *Scope) {
+	if collection.activeScopes == nil {
+		return
+	}
+
+	collection.mutex.Lock()
+	collection.activeScopes[s.id] = s
+	collection.mutex.Unlock()
+}
+
+// untrackScope removes the scope from active scopes if scope tracking is enabled.
+func (collection *ServiceCollection) untrackScope(s *Scope) {
+	if collection.activeScopes == nil {
+		return
+	}
+
+	collection.mutex.Lock()
+	delete(collection.activeScopes, s.id)
+	collection.mutex.Unlock()
+}
+
+// DebugInfo returns the current state of the collection, including registrations, active scopes and dependency graph.
+func (collection *ServiceCollection) DebugInfo() *DebugInfo {
+	info := &DebugInfo{
+		Registrations: make([]DebugRegistration, 0),
+		ScopeTracking: collection.activeScopes != nil,
+		Scopes:        make([]DebugScope, 0),
+		Graph:         collection.Graph(),
+	}
+
+	registered := make(map[string]bool)
+	for current := collection; current != nil; current = current.parent {
+		for t, serviceType := range current.registrations() {
+			if registered[t.String()] {
+				continue
+			}
+			registered[t.String()] = true
+
+			registration := DebugRegistration{
+				Type:      t.String(),
+				Lifetime:  lifetimeName(serviceType.lifetime),
+				Module:    serviceType.module,
+				Inherited: current != collection,
+			}
+
+			if serviceType.lifetime == SINGLETON {
+				current.mutex.RLock()
+				_, registration.Built = current.singletonServicePool[t]
+				current.mutex.RUnlock()
+			}
+
+			if serviceType.lifetime == POOLED {
+				stats := serviceType.pool.stats()
+				registration.Pool = &stats
+			}
+
+			info.Registrations = append(info.Registrations, registration)
+		}
+	}
+
+	sort.Slice(info.Registrations, func(i, j int) bool {
+		return info.Registrations[i].Type < info.Registrations[j].Type
+	})
+
+	collection.mutex.RLock()
+	scopes := make([]*Scope, 0, len(collection.activeScopes))
+	for _, s := range collection.activeScopes {
+		scopes = append(scopes, s)
+	}
+	collection.mutex.RUnlock()
+
+	for _, s := range scopes {
+		debugScope := DebugScope{
+			ID:        s.id,
+			Instances: make([]DebugInstance, 0),
+		}
+
+		s.mutex.RLock()
+		for id, value := range s.scopeServicePool {
+			if value == nil {
+				continue
+			}
+			debugScope.Instances = append(debugScope.Instances, DebugInstance{
+				Service: serviceTypeByID(id).String(),
+				Type:    fmt.Sprintf("%T", value),
+			})
+		}
+		s.mutex.RUnlock()
+
+		sort.Slice(debugScope.Instances, func(i, j int) bool {
+			return debugScope.Instances[i].Service < debugScope.Instances[j].Service
+		})
+
+		info.Scopes = append(info.Scopes, debugScope)
+	}
+
+	sort.Slice(info.Scopes, func(i, j int) bool {
+		return info.Scopes[i].ID < info.Scopes[j].ID
+	})
+
+	return info
+}
+
+// debugTemplate is the HTML page of the debug handler.
+var debugTemplate = template.Must(template.New("debug").Parse(`<!DOCTYPE html>
+<html>
+<head><title>Service collection</title></head>
+<body>
+<h1>Registrations</h1>
+<table border="1">
+<tr><th>Service</th><th>Lifetime</th><th>Module</th><th>Inherited</th><th>Built</th><th>Pool</th></tr>
+{{range .Registrations}}<tr><td>{{.Type}}</td><td>{{.Lifetime}}</td><td>{{.Module}}</td><td>{{.Inherited}}</td><td>{{if eq .Lifetime "singleton"}}{{.Built}}{{end}}</td><td>{{with .Pool}}idle {{.Idle}}/{{.Size}}, hits {{.Hits}}, misses {{.Misses}}, evictions {{.Evictions}}{{end}}</td></tr>
+{{end}}</table>
+<h1>Active scopes</h1>
+{{if .ScopeTracking}}{{range .Scopes}}<h2>Scope {{.ID}}</h2>
+<ul>{{range .Instances}}<li>{{.Service}}: {{.Type}}</li>{{end}}</ul>
+{{else}}<p>No active scopes.</p>
+{{end}}{{else}}<p>Scope tracking is not enabled.</p>
+{{end}}<h1>Dependency graph</h1>
+<p><a href="graph.dot">DOT</a> | <a href="graph.mmd">Mermaid</a></p>
+<pre>{{.Mermaid}}</pre>
+</body>
+</html>
+`))
+
+// debugHandler is the http.Handler that shows the state of a collection.
+type debugHandler struct {
+	collection *ServiceCollection
+}
+
+// NewDebugHandler returns an http.Handler that shows the state of the collection, it can be mounted under any path:
+//
+//	mux.Handle("/debug/di/", di.NewDebugHandler(collection))
+//
+// The state is returned as JSON or as an HTML page if the request accepts text/html.
+// The dependency graph is also available in DOT and Mermaid formats under graph.dot and graph.mmd paths.
+func NewDebugHandler(collection *ServiceCollection) http.Handler {
+	return &debugHandler{
+		collection: collection,
+	}
+}
+
+// ServeHTTP writes the state of the collection.
+func (handler *debugHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case strings.HasSuffix(r.URL.Path, "/graph.dot"):
+		w.Header().Set("Content-Type", "text/vnd.graphviz; charset=utf-8")
+		_ = handler.collection.Graph().WriteDOT(w)
+	case strings.HasSuffix(r.URL.Path, "/graph.mmd"):
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		_ = handler.collection.Graph().WriteMermaid(w)
+	case strings.Contains(r.Header.Get("Accept"), "text/html"):
+		info := handler.collection.DebugInfo()
+
+		mermaid := strings.Builder{}
+		_ = info.Graph.WriteMermaid(&mermaid)
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_ = debugTemplate.Execute(w, struct {
+			*DebugInfo
+			Mermaid string
+		}{info, mermaid.String()})
+	default:
+		w.Header().Set("Content-Type", "application/json")
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		_ = encoder.Encode(handler.collection.DebugInfo())
+	}
+}
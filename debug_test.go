@@ -0,0 +1,111 @@
+package dependency_injection
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// initDebugCollection initialize a collection with scope tracking and a scope that requested the repository.
+func initDebugCollection(t *testing.T) (*ServiceCollection, *Scope) {
+	collection := InitServiceCollection()
+	assert.Nil(t, collection.EnableScopeTracking())
+
+	err := AddSingleton[TestConfig](collection, func(s *Scope) any {
+		return TestConfig{tenant: "default"}
+	})
+	assert.Nil(t, err)
+	err = AddScoped[*TestRepository](collection, func(s *Scope) any {
+		config, _ := GetService[TestConfig](s)
+		return &TestRepository{config: config}
+	})
+	assert.Nil(t, err)
+	err = AddSingleton[TestType](collection, func(s *Scope) any {
+		return TestType{}
+	})
+	assert.Nil(t, err)
+
+	collection.Lock()
+
+	scope, err := collection.CreateScope()
+	assert.Nil(t, err)
+	_, err = GetService[*TestRepository](scope)
+	assert.Nil(t, err)
+
+	return collection, scope
+}
+
+func TestDebugInfo(t *testing.T) {
+	collection, scope := initDebugCollection(t)
+
+	info := collection.DebugInfo()
+	assert.True(t, info.ScopeTracking)
+	assert.Equal(t, []DebugRegistration{
+		{Type: "*dependency_injection.TestRepository", Lifetime: "scoped"},
+		{Type: "dependency_injection.TestConfig", Lifetime: "singleton", Built: true},
+		{Type: "dependency_injection.TestType", Lifetime: "singleton", Built: false},
+	}, info.Registrations)
+	assert.Equal(t, []DebugScope{
+		{
+			ID: scope.ID(),
+			Instances: []DebugInstance{
+				{Service: "*dependency_injection.TestRepository", Type: "*dependency_injection.TestRepository"},
+			},
+		},
+	}, info.Scopes)
+
+	assert.Nil(t, scope.Close())
+	assert.Empty(t, collection.DebugInfo().Scopes)
+}
+
+func TestDebugHandler(t *testing.T) {
+	collection, scope := initDebugCollection(t)
+
+	mux := http.NewServeMux()
+	mux.Handle("/debug/di/", NewDebugHandler(collection))
+
+	{
+		request := httptest.NewRequest(http.MethodGet, "/debug/di/", nil)
+		request.Header.Set("Accept", "application/json")
+		recorder := httptest.NewRecorder()
+		mux.ServeHTTP(recorder, request)
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+
+		info := DebugInfo{}
+		assert.Nil(t, json.Unmarshal(recorder.Body.Bytes(), &info))
+		assert.Equal(t, collection.DebugInfo().Registrations, info.Registrations)
+		assert.Equal(t, scope.ID(), info.Scopes[0].ID)
+		assert.Len(t, info.Graph.Edges, 1)
+	}
+	{
+		request := httptest.NewRequest(http.MethodGet, "/debug/di/", nil)
+		request.Header.Set("Accept", "text/html,application/xhtml+xml")
+		recorder := httptest.NewRecorder()
+		mux.ServeHTTP(recorder, request)
+
+		assert.Equal(t, "text/html; charset=utf-8", recorder.Header().Get("Content-Type"))
+		body := recorder.Body.String()
+		assert.True(t, strings.Contains(body, "<td>*dependency_injection.TestRepository</td><td>scoped</td>"))
+		assert.True(t, strings.Contains(body, "<h2>Scope "))
+		assert.True(t, strings.Contains(body, "n0 -.-&gt; n1"))
+	}
+	{
+		request := httptest.NewRequest(http.MethodGet, "/debug/di/graph.dot", nil)
+		recorder := httptest.NewRecorder()
+		mux.ServeHTTP(recorder, request)
+
+		assert.True(t, strings.HasPrefix(recorder.Body.String(), "digraph services {"))
+	}
+	{
+		request := httptest.NewRequest(http.MethodGet, "/debug/di/graph.mmd", nil)
+		recorder := httptest.NewRecorder()
+		mux.ServeHTTP(recorder, request)
+
+		assert.True(t, strings.HasPrefix(recorder.Body.String(), "graph LR"))
+	}
+}
@@ -0,0 +1,57 @@
+// Package difiber integrates go-di with fiber framework by creating a scope for every request.
+package difiber
+
+import (
+	"fmt"
+	di "github.com/ashkanabd/go-di"
+	"github.com/gofiber/fiber/v2"
+)
+
+// ScopeKey is the key that the scope of the request is stored with in fiber locals.
+const ScopeKey = "github.com/ashkanabd/go-di/difiber.scope"
+
+// Middleware creates a scope of the collection for every request and stores it in fiber locals and user context.
+// The scope will be closed after the handlers chain returns. Since fiber reuses contexts of finished requests,
+// the scope is removed from locals and the previous user context is restored before returning.
+func Middleware(collection *di.ServiceCollection) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		scope, err := collection.CreateScopeWithContext(c.UserContext())
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).
+				SendString(fmt.Sprintf("Can't create scope: %v\n", err.Error()))
+		}
+
+		userContext := c.UserContext()
+		defer func() {
+			c.Locals(ScopeKey, nil)
+			c.SetUserContext(userContext)
+			scope.Close()
+		}()
+
+		c.Locals(ScopeKey, scope)
+		c.SetUserContext(di.ContextWithScope(userContext, scope))
+
+		return c.Next()
+	}
+}
+
+// Scope returns the scope of the request which is created by Middleware.
+func Scope(c *fiber.Ctx) (*di.Scope, error) {
+	scope, ok := c.Locals(ScopeKey).(*di.Scope)
+	if !ok || scope == nil {
+		return nil, fmt.Errorf("there is no scope in fiber context")
+	}
+
+	return scope, nil
+}
+
+// Get retrieves a service from the scope of the request.
+func Get[T any](c *fiber.Ctx) (T, error) {
+	scope, err := Scope(c)
+	if err != nil {
+		var t T
+		return t, err
+	}
+
+	return di.GetService[T](scope)
+}
@@ -0,0 +1,106 @@
+package difiber
+
+import (
+	"fmt"
+	di "github.com/ashkanabd/go-di"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net/http/httptest"
+	"testing"
+)
+
+type RequestCounter struct {
+	count int
+}
+
+func initApp(t *testing.T) (*fiber.App, *[]*di.Scope) {
+	collection := di.InitServiceCollection()
+
+	err := di.AddScoped[*RequestCounter](collection, func(s *di.Scope) any {
+		return &RequestCounter{}
+	})
+	assert.Nil(t, err)
+
+	collection.Lock()
+
+	scopes := make([]*di.Scope, 0)
+
+	app := fiber.New()
+
+	// Routes outside of the group don't have the middleware
+	app.Get("/plain", func(c *fiber.Ctx) error {
+		if _, err := di.ScopeFromContext(c.UserContext()); err == nil {
+			return c.SendString("leaked scope in user context")
+		}
+		_, err := Scope(c)
+		return c.SendString(err.Error())
+	})
+
+	scoped := app.Group("/scoped", Middleware(collection))
+	scoped.Get("/", func(c *fiber.Ctx) error {
+		scope, err := Scope(c)
+		assert.Nil(t, err)
+		scopes = append(scopes, scope)
+
+		contextScope, err := di.ScopeFromContext(c.UserContext())
+		assert.Nil(t, err)
+		assert.Same(t, scope, contextScope)
+
+		counter, err := Get[*RequestCounter](c)
+		assert.Nil(t, err)
+		counter.count++
+
+		return c.SendString(fmt.Sprintf("count: %v", counter.count))
+	})
+
+	return app, &scopes
+}
+
+func request(t *testing.T, app *fiber.App, path string) string {
+	response, err := app.Test(httptest.NewRequest(fiber.MethodGet, path, nil))
+	assert.Nil(t, err)
+
+	body, err := io.ReadAll(response.Body)
+	assert.Nil(t, err)
+
+	return string(body)
+}
+
+func TestMiddleware(t *testing.T) {
+	app, scopes := initApp(t)
+
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, "count: 1", request(t, app, "/scoped"))
+	}
+
+	assert.Len(t, *scopes, 3)
+	for i, scope := range *scopes {
+		// Every request has its own scope which is closed after the request
+		_, err := di.GetService[*RequestCounter](scope)
+		assert.NotNil(t, err)
+
+		for _, other := range (*scopes)[i+1:] {
+			assert.NotSame(t, scope, other)
+		}
+	}
+}
+
+func TestScopesDoNotLeakAcrossPooledContexts(t *testing.T) {
+	app, _ := initApp(t)
+
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, "count: 1", request(t, app, "/scoped"))
+		assert.Equal(t, "there is no scope in fiber context", request(t, app, "/plain"))
+	}
+}
+
+func TestGetWithoutMiddleware(t *testing.T) {
+	app := fiber.New()
+	app.Get("/", func(c *fiber.Ctx) error {
+		_, err := Get[*RequestCounter](c)
+		return c.SendString(err.Error())
+	})
+
+	assert.Equal(t, "there is no scope in fiber context", request(t, app, "/"))
+}
@@ -0,0 +1,71 @@
+// Package digin integrates go-di with gin framework by creating a scope for every request.
+package digin
+
+import (
+	"fmt"
+	di "github.com/ashkanabd/go-di"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+// ScopeKey is the key that the scope of the request is stored with in gin context.
+const ScopeKey = "github.com/ashkanabd/go-di/digin.scope"
+
+// Middleware creates a scope of the collection for every request and stores it in gin context and request context.
+// The scope will be closed after the handlers chain returns.
+func Middleware(collection *di.ServiceCollection) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, err := collection.CreateScopeWithContext(c.Request.Context())
+		if err != nil {
+			c.String(http.StatusInternalServerError, "Can't create scope: %v\n", err.Error())
+			c.Abort()
+			return
+		}
+		defer scope.Close()
+
+		c.Set(ScopeKey, scope)
+		c.Request = c.Request.WithContext(di.ContextWithScope(c.Request.Context(), scope))
+
+		c.Next()
+	}
+}
+
+// Scope returns the scope of the request which is created by Middleware.
+func Scope(c *gin.Context) (*di.Scope, error) {
+	value, exists := c.Get(ScopeKey)
+	if !exists {
+		return nil, fmt.Errorf("there is no scope in gin context")
+	}
+
+	return value.(*di.Scope), nil
+}
+
+// Get retrieves a service from the scope of the request.
+func Get[T any](c *gin.Context) (T, error) {
+	scope, err := Scope(c)
+	if err != nil {
+		var t T
+		return t, err
+	}
+
+	return di.GetService[T](scope)
+}
+
+// Handle returns a gin handler that resolves the controller T from the scope of each request and calls given
+// handler method of it, so dependencies of the controller are resolved per request:
+//
+//	r.GET("/users", digin.Handle((*UserController).List))
+//
+// Requests without scope or failed resolutions are answered with internal server error.
+func Handle[T any](handler func(controller T, c *gin.Context)) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		controller, err := Get[T](c)
+		if err != nil {
+			c.String(http.StatusInternalServerError, "Can't resolve controller: %v\n", err.Error())
+			c.Abort()
+			return
+		}
+
+		handler(controller, c)
+	}
+}
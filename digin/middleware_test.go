@@ -0,0 +1,102 @@
+package digin
+
+import (
+	"fmt"
+	di "github.com/ashkanabd/go-di"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type RequestCounter struct {
+	count int
+}
+
+type CounterController struct {
+	counter *RequestCounter
+}
+
+func (controller *CounterController) Increase(c *gin.Context) {
+	controller.counter.count++
+	c.String(http.StatusOK, "count: %v", controller.counter.count)
+}
+
+func initRouter(t *testing.T) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	collection := di.InitServiceCollection()
+
+	err := di.AddScoped[*RequestCounter](collection, func(s *di.Scope) any {
+		return &RequestCounter{}
+	})
+	assert.Nil(t, err)
+	err = di.AddTransient[*CounterController](collection, func(s *di.Scope) any {
+		counter, _ := di.GetService[*RequestCounter](s)
+		return &CounterController{counter: counter}
+	})
+	assert.Nil(t, err)
+
+	collection.Lock()
+
+	router := gin.New()
+	router.Use(Middleware(collection))
+
+	return router
+}
+
+func TestMiddleware(t *testing.T) {
+	router := initRouter(t)
+
+	var requestScope *di.Scope
+
+	router.GET("/", func(c *gin.Context) {
+		scope, err := Scope(c)
+		assert.Nil(t, err)
+		requestScope = scope
+
+		contextScope, err := di.ScopeFromContext(c.Request.Context())
+		assert.Nil(t, err)
+		assert.Same(t, scope, contextScope)
+
+		counter1, err := Get[*RequestCounter](c)
+		assert.Nil(t, err)
+		counter2, err := Get[*RequestCounter](c)
+		assert.Nil(t, err)
+		assert.Same(t, counter1, counter2)
+	})
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	// Scope is closed after the handlers chain
+	_, err := di.GetService[*RequestCounter](requestScope)
+	assert.NotNil(t, err)
+}
+
+func TestHandle(t *testing.T) {
+	router := initRouter(t)
+	router.GET("/", Handle((*CounterController).Increase), Handle((*CounterController).Increase))
+
+	for i := 0; i < 2; i++ {
+		// Controllers of the same request share scoped dependencies and every request has its own scope
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Equal(t, "count: 1count: 2", recorder.Body.String())
+	}
+}
+
+func TestHandleWithoutMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.GET("/", Handle((*CounterController).Increase))
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+	assert.Equal(t, fmt.Sprintf("Can't resolve controller: %v\n", "there is no scope in gin context"), recorder.Body.String())
+}
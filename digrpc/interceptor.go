@@ -0,0 +1,82 @@
+// Package digrpc integrates go-di with gRPC servers by creating a scope for every call.
+package digrpc
+
+import (
+	"context"
+	di "github.com/ashkanabd/go-di"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// UnaryServerInterceptor creates a scope of the collection for every unary call and stores it in the call context.
+// The scope will be closed when the handler returns.
+func UnaryServerInterceptor(collection *di.ServiceCollection) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		scope, err := collection.CreateScopeWithContext(ctx)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "can't create scope: %v", err)
+		}
+		defer scope.Close()
+
+		return handler(di.ContextWithScope(ctx, scope), req)
+	}
+}
+
+// StreamServerInterceptor creates a scope of the collection for every stream and stores it in the stream context.
+// The scope will be closed when the stream ends.
+func StreamServerInterceptor(collection *di.ServiceCollection) grpc.StreamServerInterceptor {
+	return func(srv any, stream grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		scope, err := collection.CreateScopeWithContext(stream.Context())
+		if err != nil {
+			return status.Errorf(codes.Internal, "can't create scope: %v", err)
+		}
+		defer scope.Close()
+
+		return handler(srv, &scopedStream{
+			ServerStream: stream,
+			ctx:          di.ContextWithScope(stream.Context(), scope),
+		})
+	}
+}
+
+// scopedStream is a grpc.ServerStream whose context carries the scope of the stream.
+type scopedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context returns the context of the stream which carries the scope.
+func (stream *scopedStream) Context() context.Context {
+	return stream.ctx
+}
+
+// AddMetadata registers incoming metadata of the call as a scoped metadata.MD service.
+// Metadata is empty for scopes which are not created by the interceptors.
+func AddMetadata(collection *di.ServiceCollection) error {
+	return di.AddScoped[metadata.MD](collection, func(s *di.Scope) any {
+		md, ok := metadata.FromIncomingContext(s.Context())
+		if !ok {
+			return metadata.MD{}
+		}
+
+		return md
+	})
+}
+
+// Scope returns the scope of the call which is created by the interceptors.
+func Scope(ctx context.Context) (*di.Scope, error) {
+	return di.ScopeFromContext(ctx)
+}
+
+// Get retrieves a service from the scope of the call.
+func Get[T any](ctx context.Context) (T, error) {
+	scope, err := Scope(ctx)
+	if err != nil {
+		var t T
+		return t, err
+	}
+
+	return di.GetService[T](scope)
+}
@@ -0,0 +1,148 @@
+package digrpc
+
+import (
+	"context"
+	di "github.com/ashkanabd/go-di"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/test/bufconn"
+	"net"
+	"testing"
+)
+
+type CallCounter struct {
+	count int
+}
+
+// healthServer reports the service name of the metadata as the status of requested service.
+type healthServer struct {
+	grpc_health_v1.UnimplementedHealthServer
+	t      *testing.T
+	scopes []*di.Scope
+}
+
+func (server *healthServer) status(ctx context.Context, service string) grpc_health_v1.HealthCheckResponse_ServingStatus {
+	scope, err := Scope(ctx)
+	assert.Nil(server.t, err)
+	server.scopes = append(server.scopes, scope)
+
+	counter, err := Get[*CallCounter](ctx)
+	assert.Nil(server.t, err)
+	counter.count++
+	again, _ := Get[*CallCounter](ctx)
+	assert.Equal(server.t, 1, again.count)
+
+	md, err := Get[metadata.MD](ctx)
+	assert.Nil(server.t, err)
+
+	if values := md.Get("service"); len(values) == 1 && values[0] == service {
+		return grpc_health_v1.HealthCheckResponse_SERVING
+	}
+
+	return grpc_health_v1.HealthCheckResponse_NOT_SERVING
+}
+
+func (server *healthServer) Check(ctx context.Context, req *grpc_health_v1.HealthCheckRequest) (*grpc_health_v1.HealthCheckResponse, error) {
+	return &grpc_health_v1.HealthCheckResponse{Status: server.status(ctx, req.Service)}, nil
+}
+
+func (server *healthServer) Watch(req *grpc_health_v1.HealthCheckRequest, stream grpc_health_v1.Health_WatchServer) error {
+	return stream.Send(&grpc_health_v1.HealthCheckResponse{Status: server.status(stream.Context(), req.Service)})
+}
+
+func initServer(t *testing.T) (*di.ServiceCollection, *healthServer, grpc_health_v1.HealthClient) {
+	collection := di.InitServiceCollection()
+
+	err := di.AddScoped[*CallCounter](collection, func(s *di.Scope) any {
+		return &CallCounter{}
+	})
+	assert.Nil(t, err)
+	assert.Nil(t, AddMetadata(collection))
+
+	collection.Lock()
+
+	listener := bufconn.Listen(1024 * 1024)
+	server := grpc.NewServer(
+		grpc.UnaryInterceptor(UnaryServerInterceptor(collection)),
+		grpc.StreamInterceptor(StreamServerInterceptor(collection)),
+	)
+	health := &healthServer{t: t}
+	grpc_health_v1.RegisterHealthServer(server, health)
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	assert.Nil(t, err)
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+
+	return collection, health, grpc_health_v1.NewHealthClient(conn)
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	collection, health, client := initServer(t)
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "service", "users")
+
+	response, err := client.Check(ctx, &grpc_health_v1.HealthCheckRequest{Service: "users"})
+	assert.Nil(t, err)
+	assert.Equal(t, grpc_health_v1.HealthCheckResponse_SERVING, response.Status)
+
+	response, err = client.Check(ctx, &grpc_health_v1.HealthCheckRequest{Service: "orders"})
+	assert.Nil(t, err)
+	assert.Equal(t, grpc_health_v1.HealthCheckResponse_NOT_SERVING, response.Status)
+
+	assert.Len(t, health.scopes, 2)
+	assert.NotEqual(t, health.scopes[0].ID(), health.scopes[1].ID())
+
+	_, err = di.GetService[*CallCounter](health.scopes[0])
+	assert.NotNil(t, err, "scope should be closed after the call")
+	assert.Equal(t, int64(0), collection.Metrics().LiveScopes)
+}
+
+func TestStreamServerInterceptor(t *testing.T) {
+	collection, health, client := initServer(t)
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "service", "users")
+
+	stream, err := client.Watch(ctx, &grpc_health_v1.HealthCheckRequest{Service: "users"})
+	assert.Nil(t, err)
+
+	response, err := stream.Recv()
+	assert.Nil(t, err)
+	assert.Equal(t, grpc_health_v1.HealthCheckResponse_SERVING, response.Status)
+
+	_, err = stream.Recv()
+	assert.NotNil(t, err, "stream should be ended")
+
+	assert.Len(t, health.scopes, 1)
+	_, err = di.GetService[*CallCounter](health.scopes[0])
+	assert.NotNil(t, err, "scope should be closed after the stream ends")
+	assert.Equal(t, int64(0), collection.Metrics().LiveScopes)
+}
+
+func TestInterceptorsWithoutLock(t *testing.T) {
+	collection := di.InitServiceCollection()
+
+	_, err := UnaryServerInterceptor(collection)(context.Background(), nil, nil, func(ctx context.Context, req any) (any, error) {
+		t.Fatal("handler should not be called")
+		return nil, nil
+	})
+	assert.NotNil(t, err)
+}
+
+func TestGetWithoutScope(t *testing.T) {
+	_, err := Get[metadata.MD](context.Background())
+	assert.NotNil(t, err)
+}
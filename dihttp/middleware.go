@@ -0,0 +1,55 @@
+// Package dihttp integrates go-di with net/http by creating a scope for every request.
+package dihttp
+
+import (
+	"fmt"
+	di "github.com/ashkanabd/go-di"
+	"net/http"
+)
+
+// Middleware creates a scope of the collection for every request and stores it in the request context.
+// The scope will be closed when the next handler returns.
+func Middleware(collection *di.ServiceCollection) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			scope, err := collection.CreateScopeWithContext(r.Context())
+			if err != nil {
+				http.Error(w, fmt.Sprintf("Can't create scope: %v", err.Error()), http.StatusInternalServerError)
+				return
+			}
+			defer scope.Close()
+
+			next.ServeHTTP(w, r.WithContext(di.ContextWithScope(r.Context(), scope)))
+		})
+	}
+}
+
+// Scope returns the scope of the request which is created by Middleware.
+func Scope(r *http.Request) (*di.Scope, error) {
+	return di.ScopeFromContext(r.Context())
+}
+
+// Get retrieves a service from the scope of the request.
+func Get[T any](r *http.Request) (T, error) {
+	scope, err := Scope(r)
+	if err != nil {
+		var t T
+		return t, err
+	}
+
+	return di.GetService[T](scope)
+}
+
+// Handler returns an http.Handler that resolves the handler service T from the scope of each request and invokes it.
+// Requests without scope or failed resolutions are answered with internal server error.
+func Handler[T http.Handler]() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handler, err := Get[T](r)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Can't resolve handler: %v", err.Error()), http.StatusInternalServerError)
+			return
+		}
+
+		handler.ServeHTTP(w, r)
+	})
+}
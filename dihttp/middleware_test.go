@@ -0,0 +1,100 @@
+package dihttp
+
+import (
+	"fmt"
+	di "github.com/ashkanabd/go-di"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type RequestCounter struct {
+	count int
+}
+
+type CounterHandler struct {
+	counter *RequestCounter
+}
+
+func (handler *CounterHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	handler.counter.count++
+	io.WriteString(w, fmt.Sprintf("count: %v", handler.counter.count))
+}
+
+func initCollection(t *testing.T) *di.ServiceCollection {
+	collection := di.InitServiceCollection()
+
+	err := di.AddScoped[*RequestCounter](collection, func(s *di.Scope) any {
+		return &RequestCounter{}
+	})
+	assert.Nil(t, err)
+	err = di.AddTransient[*CounterHandler](collection, func(s *di.Scope) any {
+		counter, _ := di.GetService[*RequestCounter](s)
+		return &CounterHandler{counter: counter}
+	})
+	assert.Nil(t, err)
+
+	collection.Lock()
+
+	return collection
+}
+
+func TestMiddleware(t *testing.T) {
+	collection := initCollection(t)
+
+	var requestScope *di.Scope
+
+	handler := Middleware(collection)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		scope, err := Scope(r)
+		assert.Nil(t, err)
+		requestScope = scope
+
+		counter1, err := Get[*RequestCounter](r)
+		assert.Nil(t, err)
+		counter2, err := Get[*RequestCounter](r)
+		assert.Nil(t, err)
+		assert.Same(t, counter1, counter2)
+	}))
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	// Scope is closed after the response
+	_, err := di.GetService[*RequestCounter](requestScope)
+	assert.NotNil(t, err)
+}
+
+func TestHandler(t *testing.T) {
+	collection := initCollection(t)
+
+	handler := Middleware(collection)(Handler[*CounterHandler]())
+
+	for i := 0; i < 2; i++ {
+		// Every request has its own scope
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Equal(t, "count: 1", recorder.Body.String())
+	}
+}
+
+func TestHandlerWithoutMiddleware(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	Handler[*CounterHandler]().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+	assert.Equal(t, "Can't resolve handler: there is no scope in context\n", recorder.Body.String())
+}
+
+func TestMiddlewareWithUnlockedCollection(t *testing.T) {
+	handler := Middleware(di.InitServiceCollection())(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Fatal("handler should not be called")
+	}))
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+}
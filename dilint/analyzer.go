@@ -0,0 +1,375 @@
+// Package dilint provides a go/analysis analyzer that checks registrations and lookups of go-di services.
+package dilint
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+	"sort"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+// diPath is the import path of go-di, lookup functions of its integration packages are checked too.
+const diPath = "github.com/ashkanabd/go-di"
+
+// registrationFunctions are the functions of go-di that register services by the index of their provider argument,
+// -1 for functions without provider.
+var registrationFunctions = map[string]int{
+	"AddOptions":          -1,
+	"AddSingleton":        1,
+	"AddSingletonWithTTL": 2,
+	"AddScoped":           1,
+	"AddTransient":        1,
+	"AddPooled":           1,
+}
+
+// Analyzer reports lookups of services that are never registered, services that are registered more than once in
+// the same collection and providers whose returned values are not of the registered type.
+//
+// Registrations and lookups of imported packages are exported as facts, so lookups are checked in main packages
+// against the registrations of the whole program. Main packages report services which are registered by more than
+// one package too, since packages usually register their services into the collection which is passed to them.
+var Analyzer = &analysis.Analyzer{
+	Name:      "dilint",
+	Doc:       "check registrations and lookups of go-di services",
+	Requires:  []*analysis.Analyzer{inspect.Analyzer},
+	FactTypes: []analysis.Fact{new(servicesFact)},
+	Run:       run,
+}
+
+// servicesFact is the registrations and lookups of a package and its dependencies.
+type servicesFact struct {
+	// Registrations by service type.
+	Registrations map[string][]registration
+	// Positions of lookups by service type.
+	Lookups map[string][]string
+}
+
+// registration is a call that registers a service.
+type registration struct {
+	// Import path of the package that registers the service.
+	Package string
+	// Position of the call.
+	Position string
+}
+
+// AFact marks servicesFact as a fact.
+func (*servicesFact) AFact() {}
+
+func (fact *servicesFact) String() string {
+	return fmt.Sprintf("services(%v registrations, %v lookups)", len(fact.Registrations), len(fact.Lookups))
+}
+
+// lookup is a call that requests a service.
+type lookup struct {
+	call    *ast.CallExpr
+	service string
+}
+
+func run(pass *analysis.Pass) (any, error) {
+	fact := &servicesFact{
+		Registrations: make(map[string][]registration),
+		Lookups:       make(map[string][]string),
+	}
+	for _, imported := range pass.Pkg.Imports() {
+		importedFact := new(servicesFact)
+		if pass.ImportPackageFact(imported, importedFact) {
+			fact.merge(importedFact)
+		}
+	}
+
+	lookups := make([]lookup, 0)
+	// Registered services by collection, to find services which are registered more than once.
+	registered := make(map[types.Object]map[string]bool)
+
+	inspect := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+	inspect.Preorder([]ast.Node{(*ast.CallExpr)(nil)}, func(node ast.Node) {
+		call := node.(*ast.CallExpr)
+
+		function, service := calledFunction(pass, call)
+		if function == nil || service == nil || hasTypeParam(service) {
+			// Generic wrappers of go-di functions are checked where they are instantiated.
+			return
+		}
+
+		name := function.Name()
+		serviceName := types.TypeString(service, nil)
+
+		if function.Pkg().Path() == diPath {
+			if providerIndex, isRegistration := registrationFunctions[name]; isRegistration {
+				r := registration{Package: pass.Pkg.Path(), Position: pass.Fset.Position(call.Pos()).String()}
+				fact.Registrations[serviceName] = append(fact.Registrations[serviceName], r)
+				checkDuplicate(pass, call, serviceName, registered)
+				if name == "AddOptions" {
+					// Options are registered as Options[T] too.
+					if wrapper := optionsWrapper(function.Pkg(), service); wrapper != "" {
+						fact.Registrations[wrapper] = append(fact.Registrations[wrapper], r)
+					}
+				}
+				if providerIndex >= 0 && providerIndex < len(call.Args) {
+					checkProvider(pass, call.Args[providerIndex], service)
+				}
+				return
+			}
+		}
+
+		if isLookup(function) {
+			lookups = append(lookups, lookup{call: call, service: serviceName})
+			fact.Lookups[serviceName] = append(fact.Lookups[serviceName], pass.Fset.Position(call.Pos()).String())
+		}
+	})
+
+	pass.ExportPackageFact(fact)
+
+	if pass.Pkg.Name() != "main" {
+		return nil, nil
+	}
+
+	for _, l := range lookups {
+		if len(fact.Registrations[l.service]) == 0 {
+			pass.Reportf(l.call.Pos(), "service %v is never registered", l.service)
+		}
+	}
+
+	// Lookups of imported packages can't be reported at their positions, so they are reported at the package clause.
+	local := make(map[string]bool)
+	for _, l := range lookups {
+		local[pass.Fset.Position(l.call.Pos()).String()] = true
+	}
+	services := make([]string, 0, len(fact.Lookups))
+	for service := range fact.Lookups {
+		services = append(services, service)
+	}
+	sort.Strings(services)
+
+	for _, service := range services {
+		if len(fact.Registrations[service]) > 0 {
+			continue
+		}
+		for _, position := range fact.Lookups[service] {
+			if !local[position] && len(pass.Files) > 0 {
+				pass.Reportf(pass.Files[0].Package, "service %v requested at %v is never registered", service, position)
+			}
+		}
+	}
+
+	checkDuplicatePackages(pass, fact)
+
+	return nil, nil
+}
+
+// checkDuplicatePackages reports services which are registered by more than one package of the program at the
+// package clause, duplicates in the same package are reported by checkDuplicate.
+func checkDuplicatePackages(pass *analysis.Pass, fact *servicesFact) {
+	if len(pass.Files) == 0 {
+		return
+	}
+
+	services := make([]string, 0, len(fact.Registrations))
+	for service := range fact.Registrations {
+		services = append(services, service)
+	}
+	sort.Strings(services)
+
+	for _, service := range services {
+		registrations := append([]registration(nil), fact.Registrations[service]...)
+		sort.Slice(registrations, func(i, j int) bool {
+			return registrations[i].Position < registrations[j].Position
+		})
+
+		first := registrations[0]
+		for _, r := range registrations[1:] {
+			if r.Package != first.Package {
+				pass.Reportf(pass.Files[0].Package, "service %v registered at %v is already registered at %v",
+					service, r.Position, first.Position)
+			}
+		}
+	}
+}
+
+// merge adds registrations and lookups of an other fact to the fact.
+func (fact *servicesFact) merge(other *servicesFact) {
+	for service, registrations := range other.Registrations {
+		known := make(map[string]bool)
+		for _, r := range fact.Registrations[service] {
+			known[r.Position] = true
+		}
+		for _, r := range registrations {
+			if !known[r.Position] {
+				fact.Registrations[service] = append(fact.Registrations[service], r)
+			}
+		}
+	}
+
+	for service, positions := range other.Lookups {
+		known := make(map[string]bool)
+		for _, position := range fact.Lookups[service] {
+			known[position] = true
+		}
+		for _, position := range positions {
+			if !known[position] {
+				fact.Lookups[service] = append(fact.Lookups[service], position)
+			}
+		}
+	}
+}
+
+// calledFunction returns the generic function of go-di or its integration packages that is called and its first
+// type argument, nil if the call isn't a call of such a function.
+func calledFunction(pass *analysis.Pass, call *ast.CallExpr) (*types.Func, types.Type) {
+	fun := ast.Unparen(call.Fun)
+	if index, ok := fun.(*ast.IndexExpr); ok {
+		fun = index.X
+	} else if index, ok := fun.(*ast.IndexListExpr); ok {
+		fun = index.X
+	}
+
+	var ident *ast.Ident
+	switch f := fun.(type) {
+	case *ast.Ident:
+		ident = f
+	case *ast.SelectorExpr:
+		ident = f.Sel
+	default:
+		return nil, nil
+	}
+
+	function, ok := pass.TypesInfo.Uses[ident].(*types.Func)
+	if !ok || function.Pkg() == nil || !isDIPackage(function.Pkg().Path()) {
+		return nil, nil
+	}
+
+	instance, ok := pass.TypesInfo.Instances[ident]
+	if !ok || instance.TypeArgs.Len() == 0 {
+		return function, nil
+	}
+
+	return function, instance.TypeArgs.At(0)
+}
+
+// optionsWrapper returns the name of Options type of go-di instantiated with given options type, empty if it can't
+// be instantiated.
+func optionsWrapper(pkg *types.Package, options types.Type) string {
+	object := pkg.Scope().Lookup("Options")
+	if object == nil {
+		return ""
+	}
+
+	wrapper, err := types.Instantiate(nil, object.Type(), []types.Type{options}, false)
+	if err != nil {
+		return ""
+	}
+
+	return types.TypeString(wrapper, nil)
+}
+
+// hasTypeParam reports whether the type refers to a type parameter.
+func hasTypeParam(t types.Type) bool {
+	switch t := t.(type) {
+	case *types.TypeParam:
+		return true
+	case *types.Pointer:
+		return hasTypeParam(t.Elem())
+	case *types.Slice:
+		return hasTypeParam(t.Elem())
+	case *types.Array:
+		return hasTypeParam(t.Elem())
+	case *types.Chan:
+		return hasTypeParam(t.Elem())
+	case *types.Map:
+		return hasTypeParam(t.Key()) || hasTypeParam(t.Elem())
+	case *types.Named:
+		for i := 0; i < t.TypeArgs().Len(); i++ {
+			if hasTypeParam(t.TypeArgs().At(i)) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// isDIPackage reports whether the import path is go-di or one of its packages.
+func isDIPackage(path string) bool {
+	return path == diPath || len(path) > len(diPath) && path[:len(diPath)+1] == diPath+"/"
+}
+
+// isLookup reports whether the function requests a service from a scope.
+func isLookup(function *types.Func) bool {
+	if function.Pkg().Path() == diPath {
+		return function.Name() == "GetService"
+	}
+
+	return function.Name() == "Get"
+}
+
+// checkDuplicate reports a registration of a service which is already registered in the same collection.
+func checkDuplicate(pass *analysis.Pass, call *ast.CallExpr, service string, registered map[types.Object]map[string]bool) {
+	if len(call.Args) == 0 {
+		return
+	}
+
+	ident, ok := ast.Unparen(call.Args[0]).(*ast.Ident)
+	if !ok {
+		return
+	}
+
+	collection := pass.TypesInfo.ObjectOf(ident)
+	if collection == nil {
+		return
+	}
+
+	if registered[collection] == nil {
+		registered[collection] = make(map[string]bool)
+	}
+	if registered[collection][service] {
+		pass.Reportf(call.Pos(), "service %v is already registered in %v", service, ident.Name)
+		return
+	}
+	registered[collection][service] = true
+}
+
+// checkProvider reports returned values of a provider literal which are not of the registered service type.
+// Returned errors are skipped since providers return errors to report failures.
+func checkProvider(pass *analysis.Pass, provider ast.Expr, service types.Type) {
+	literal, ok := ast.Unparen(provider).(*ast.FuncLit)
+	if !ok {
+		return
+	}
+
+	errorType := types.Universe.Lookup("error").Type().Underlying().(*types.Interface)
+	iface, isInterface := service.Underlying().(*types.Interface)
+
+	ast.Inspect(literal.Body, func(node ast.Node) bool {
+		switch n := node.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.ReturnStmt:
+			if len(n.Results) != 1 {
+				return true
+			}
+
+			returned := pass.TypesInfo.TypeOf(n.Results[0])
+			if returned == nil || types.Identical(returned, types.Typ[types.UntypedNil]) || types.IsInterface(returned) {
+				return true
+			}
+
+			isService := types.Identical(returned, service)
+			if isInterface {
+				isService = types.Implements(returned, iface)
+			}
+
+			switch {
+			case isService || types.Implements(returned, errorType):
+			case isInterface:
+				pass.Reportf(n.Results[0].Pos(), "provider returns %v which does not implement %v", returned, service)
+			default:
+				pass.Reportf(n.Results[0].Pos(), "provider returns %v which is not %v", returned, service)
+			}
+		}
+		return true
+	})
+}
@@ -0,0 +1,13 @@
+// Command dilint checks registrations and lookups of go-di services, it can be run directly or by go vet:
+//
+//	go vet -vettool=$(which dilint) ./...
+package main
+
+import (
+	"github.com/ashkanabd/go-di/dilint"
+	"golang.org/x/tools/go/analysis/singlechecker"
+)
+
+func main() {
+	singlechecker.Main(dilint.Analyzer)
+}
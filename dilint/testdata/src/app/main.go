@@ -0,0 +1,20 @@
+package main // want package:`services\(6 registrations, 6 lookups\)` `service \*handlers.AuditLog requested at .*handlers.go:\d+:\d+ is never registered` `service \*handlers.Mailer registered at .*registrations.go:\d+:\d+ is already registered at .*jobs.go:\d+:\d+`
+
+import (
+	di "github.com/ashkanabd/go-di"
+	"jobs"
+	"registrations"
+)
+
+type Clock struct{}
+
+func main() {
+	collection := di.InitServiceCollection()
+	registrations.Register(collection)
+	jobs.Register(collection)
+
+	var s *di.Scope
+	_, _ = di.GetService[registrations.Repository](s)
+	_, _ = di.GetService[di.Options[registrations.ServerOptions]](s)
+	_, _ = di.GetService[*Clock](s) // want `service \*app.Clock is never registered`
+}
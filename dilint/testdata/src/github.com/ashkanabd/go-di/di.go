@@ -0,0 +1,43 @@
+// Package dependency_injection is a stub of go-di for tests.
+package dependency_injection
+
+import "time"
+
+type ServiceCollection struct{}
+
+type Scope struct{}
+
+type ServiceOption func()
+
+func InitServiceCollection() *ServiceCollection { return nil }
+
+func AddSingleton[T any](collection *ServiceCollection, provider func(s *Scope) any, opts ...ServiceOption) error {
+	return nil
+}
+
+func AddSingletonWithTTL[T any](collection *ServiceCollection, ttl time.Duration, provider func(s *Scope) any, opts ...ServiceOption) error {
+	return nil
+}
+
+func AddScoped[T any](collection *ServiceCollection, provider func(s *Scope) any, opts ...ServiceOption) error {
+	return nil
+}
+
+func AddTransient[T any](collection *ServiceCollection, provider func(s *Scope) any, opts ...ServiceOption) error {
+	return nil
+}
+
+func AddPooled[T any](collection *ServiceCollection, provider func(s *Scope) any, reset func(T), opts ...ServiceOption) error {
+	return nil
+}
+
+type Options[T any] struct{}
+
+func AddOptions[T any](collection *ServiceCollection, section string, opts ...ServiceOption) error {
+	return nil
+}
+
+func GetService[T any](s *Scope) (T, error) {
+	var t T
+	return t, nil
+}
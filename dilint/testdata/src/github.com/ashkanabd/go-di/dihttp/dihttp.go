@@ -0,0 +1,9 @@
+// Package dihttp is a stub of go-di/dihttp for tests.
+package dihttp
+
+import "net/http"
+
+func Get[T any](r *http.Request) (T, error) {
+	var t T
+	return t, nil
+}
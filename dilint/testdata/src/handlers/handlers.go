@@ -0,0 +1,28 @@
+package handlers // want package:`services\(0 registrations, 3 lookups\)`
+
+import (
+	"net/http"
+
+	di "github.com/ashkanabd/go-di"
+	"github.com/ashkanabd/go-di/dihttp"
+)
+
+type UserService struct{}
+
+type AuditLog struct{}
+
+type Mailer struct{}
+
+func Users(w http.ResponseWriter, r *http.Request) {
+	_, _ = dihttp.Get[*UserService](r)
+	_, _ = dihttp.Get[*AuditLog](r)
+}
+
+func Notify(s *di.Scope) {
+	_, _ = di.GetService[*Mailer](s)
+}
+
+func Resolve[T any](s *di.Scope) T {
+	t, _ := di.GetService[T](s)
+	return t
+}
@@ -0,0 +1,12 @@
+package jobs // want package:`services\(1 registrations, 3 lookups\)`
+
+import (
+	di "github.com/ashkanabd/go-di"
+	"handlers"
+)
+
+func Register(collection *di.ServiceCollection) {
+	_ = di.AddPooled[*handlers.Mailer](collection, func(s *di.Scope) any {
+		return &handlers.Mailer{}
+	}, nil)
+}
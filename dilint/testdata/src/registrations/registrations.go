@@ -0,0 +1,47 @@
+package registrations // want package:`services\(6 registrations, 3 lookups\)`
+
+import (
+	"errors"
+
+	di "github.com/ashkanabd/go-di"
+	"handlers"
+)
+
+type Repository interface {
+	Find() string
+}
+
+type SQLRepository struct{}
+
+func (repository *SQLRepository) Find() string { return "" }
+
+type Config struct{}
+
+type ServerOptions struct{}
+
+func Register(collection *di.ServiceCollection) {
+	_ = di.AddScoped[Repository](collection, func(s *di.Scope) any {
+		if false {
+			return errors.New("not ready")
+		}
+		return &SQLRepository{}
+	})
+	_ = di.AddSingleton[Config](collection, func(s *di.Scope) any {
+		return &Config{} // want `provider returns \*registrations.Config which is not registrations.Config`
+	})
+	_ = di.AddTransient[*handlers.UserService](collection, func(s *di.Scope) any {
+		return &handlers.UserService{}
+	})
+	_ = di.AddOptions[ServerOptions](collection, "server")
+	_ = di.AddPooled[*handlers.Mailer](collection, func(s *di.Scope) any {
+		return &handlers.Mailer{}
+	}, nil)
+	_ = di.AddTransient[*handlers.UserService](collection, func(s *di.Scope) any { // want `service \*handlers.UserService is already registered in collection`
+		return &handlers.UserService{}
+	})
+
+	child := di.InitServiceCollection()
+	_ = di.AddScoped[Repository](child, func(s *di.Scope) any {
+		return SQLRepository{} // want `provider returns registrations.SQLRepository which does not implement registrations.Repository`
+	})
+}
@@ -0,0 +1,18 @@
+module github.com/ashkanabd/go-di/dilogrus
+
+go 1.18
+
+require (
+	github.com/ashkanabd/go-di v0.0.0-20220826085616-e156203e47c7
+	github.com/sirupsen/logrus v1.9.0
+	github.com/stretchr/testify v1.8.0
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	golang.org/x/sys v0.0.0-20220715151400-c0bba94af5f8 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+replace github.com/ashkanabd/go-di => ../
@@ -0,0 +1,34 @@
+// Package dilogrus provides a go-di Logger that writes logs with logrus.
+package dilogrus
+
+import (
+	di "github.com/ashkanabd/go-di"
+	"github.com/sirupsen/logrus"
+)
+
+// logger is a di.Logger that writes logs with logrus.
+type logger struct {
+	entry *logrus.Entry
+}
+
+// NewLogger returns a di.Logger that writes debug logs with given logrus logger.
+func NewLogger(l *logrus.Logger) di.Logger {
+	return &logger{
+		entry: logrus.NewEntry(l),
+	}
+}
+
+// Enabled reports whether the logrus logger writes debug level logs.
+func (l *logger) Enabled() bool {
+	return l.entry.Logger.IsLevelEnabled(logrus.DebugLevel)
+}
+
+// Debug writes a debug log with fields as logrus fields.
+func (l *logger) Debug(msg string, fields ...di.Field) {
+	logrusFields := make(logrus.Fields, len(fields))
+	for _, field := range fields {
+		logrusFields[field.Key] = field.Value
+	}
+
+	l.entry.WithFields(logrusFields).Debug(msg)
+}
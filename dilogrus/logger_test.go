@@ -0,0 +1,28 @@
+package dilogrus
+
+import (
+	"bytes"
+	di "github.com/ashkanabd/go-di"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestLogger(t *testing.T) {
+	buffer := bytes.Buffer{}
+	logrusLogger := logrus.New()
+	logrusLogger.SetOutput(&buffer)
+	logrusLogger.SetFormatter(&logrus.TextFormatter{DisableTimestamp: true})
+
+	logger := NewLogger(logrusLogger)
+	assert.False(t, logger.Enabled())
+
+	logrusLogger.SetLevel(logrus.DebugLevel)
+	assert.True(t, logger.Enabled())
+
+	logger.Debug("Providing scoped value",
+		di.Field{Key: di.FieldService, Value: "main.Service"},
+		di.Field{Key: di.FieldLifetime, Value: "scoped"},
+	)
+	assert.Equal(t, "level=debug msg=\"Providing scoped value\" lifetime=scoped service=main.Service\n", buffer.String())
+}
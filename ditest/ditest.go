@@ -0,0 +1,56 @@
+// Package ditest helps tests to replace registrations of a configured collection with fakes.
+package ditest
+
+import (
+	di "github.com/ashkanabd/go-di"
+	"github.com/ashkanabd/go-di/internal/testhook"
+	"reflect"
+	"sync/atomic"
+	"testing"
+)
+
+// Clone returns a clone of the collection for the test, so overrides of the test don't affect other tests.
+// The clone is locked if the collection is locked.
+func Clone(t testing.TB, collection *di.ServiceCollection) *di.ServiceCollection {
+	t.Helper()
+
+	return testhook.Clone(collection).(*di.ServiceCollection)
+}
+
+// Override replaces the provider of registered service T in the collection for the duration of the test,
+// even if the collection is locked. The previous registration is restored when the test and its subtests finish,
+// and the test fails if the overridden service was never resolved.
+// Singletons which are initialized before the override keep their dependencies, so override services before
+// resolving their dependents.
+func Override[T any](t testing.TB, collection *di.ServiceCollection, provider func(s *di.Scope) any) {
+	t.Helper()
+
+	resolutions := int64(0)
+	serviceType := reflect.TypeOf((*T)(nil)).Elem()
+	restore, err := testhook.Replace(collection, serviceType, func(s *di.Scope) any {
+		atomic.AddInt64(&resolutions, 1)
+		return provider(s)
+	})
+	if err != nil {
+		t.Fatalf("Can't override service: %v", err)
+		return
+	}
+
+	t.Cleanup(func() {
+		restore()
+
+		if atomic.LoadInt64(&resolutions) == 0 {
+			t.Errorf("Override of service %v was never resolved", serviceType)
+		}
+	})
+}
+
+// OverrideValue replaces registered service T in the collection with given value for the duration of the test,
+// like Override.
+func OverrideValue[T any](t testing.TB, collection *di.ServiceCollection, value T) {
+	t.Helper()
+
+	Override[T](t, collection, func(s *di.Scope) any {
+		return value
+	})
+}
@@ -0,0 +1,132 @@
+package ditest
+
+import (
+	"fmt"
+	di "github.com/ashkanabd/go-di"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+type PaymentGateway interface {
+	Charge(amount int) error
+}
+
+type StripeGateway struct{}
+
+func (gateway *StripeGateway) Charge(amount int) error {
+	return fmt.Errorf("network is not available in tests")
+}
+
+type FakeGateway struct {
+	charged []int
+}
+
+func (gateway *FakeGateway) Charge(amount int) error {
+	gateway.charged = append(gateway.charged, amount)
+	return nil
+}
+
+type CheckoutService struct {
+	gateway PaymentGateway
+}
+
+func initCollection(t *testing.T) *di.ServiceCollection {
+	collection := di.InitServiceCollection()
+
+	err := di.AddSingleton[PaymentGateway](collection, func(s *di.Scope) any {
+		return &StripeGateway{}
+	})
+	assert.Nil(t, err)
+	err = di.AddScoped[*CheckoutService](collection, func(s *di.Scope) any {
+		gateway, _ := di.GetService[PaymentGateway](s)
+		return &CheckoutService{gateway: gateway}
+	})
+	assert.Nil(t, err)
+
+	collection.Lock()
+
+	return collection
+}
+
+func resolveGateway(t *testing.T, collection *di.ServiceCollection) PaymentGateway {
+	scope, err := collection.CreateScope()
+	assert.Nil(t, err)
+	defer scope.Close()
+
+	checkout, err := di.GetService[*CheckoutService](scope)
+	assert.Nil(t, err)
+
+	return checkout.gateway
+}
+
+func TestOverride(t *testing.T) {
+	collection := initCollection(t)
+	clone := Clone(t, collection)
+	assert.NotNil(t, di.AddTransient[*FakeGateway](clone, func(s *di.Scope) any {
+		return &FakeGateway{}
+	}), "clone of a locked collection should be locked")
+
+	fake := &FakeGateway{}
+
+	t.Run("overridden", func(t *testing.T) {
+		OverrideValue[PaymentGateway](t, clone, fake)
+
+		assert.Nil(t, resolveGateway(t, clone).Charge(10))
+		assert.Equal(t, []int{10}, fake.charged)
+	})
+
+	_, isStripe := resolveGateway(t, clone).(*StripeGateway)
+	assert.True(t, isStripe, "registration should be restored after the test")
+	_, isStripe = resolveGateway(t, collection).(*StripeGateway)
+	assert.True(t, isStripe, "original collection should not be affected")
+}
+
+// recordingT records the failures of a test.
+type recordingT struct {
+	testing.TB
+	errors   []string
+	cleanups []func()
+}
+
+func (t *recordingT) Helper() {}
+
+func (t *recordingT) Errorf(format string, args ...any) {
+	t.errors = append(t.errors, fmt.Sprintf(format, args...))
+}
+
+func (t *recordingT) Fatalf(format string, args ...any) {
+	t.errors = append(t.errors, fmt.Sprintf(format, args...))
+}
+
+func (t *recordingT) Cleanup(f func()) {
+	t.cleanups = append(t.cleanups, f)
+}
+
+func (t *recordingT) finish() {
+	for i := len(t.cleanups) - 1; i >= 0; i-- {
+		t.cleanups[i]()
+	}
+}
+
+func TestOverrideNotResolved(t *testing.T) {
+	clone := Clone(t, initCollection(t))
+
+	recorder := &recordingT{}
+	Override[PaymentGateway](recorder, clone, func(s *di.Scope) any {
+		return &FakeGateway{}
+	})
+	recorder.finish()
+
+	assert.Equal(t, []string{"Override of service ditest.PaymentGateway was never resolved"}, recorder.errors)
+}
+
+func TestOverrideNotRegistered(t *testing.T) {
+	clone := Clone(t, initCollection(t))
+
+	recorder := &recordingT{}
+	OverrideValue[*FakeGateway](recorder, clone, &FakeGateway{})
+	recorder.finish()
+
+	assert.Len(t, recorder.errors, 1)
+	assert.Contains(t, recorder.errors[0], "is not registered")
+}
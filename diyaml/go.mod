@@ -0,0 +1,16 @@
+module github.com/ashkanabd/go-di/diyaml
+
+go 1.18
+
+require (
+	github.com/ashkanabd/go-di v0.0.0-20220826085616-e156203e47c7
+	github.com/stretchr/testify v1.8.0
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+)
+
+replace github.com/ashkanabd/go-di => ../
@@ -0,0 +1,36 @@
+// Package diyaml provides a go-di ConfigSource that reads YAML files.
+package diyaml
+
+import (
+	"fmt"
+	di "github.com/ashkanabd/go-di"
+	"gopkg.in/yaml.v3"
+	"os"
+)
+
+// fileSource is a di.ConfigSource of the values of a YAML file.
+type fileSource struct {
+	path string
+}
+
+// FileSource returns a di.ConfigSource of the values of a YAML file, mappings of the file are sections.
+func FileSource(path string) di.ConfigSource {
+	return &fileSource{
+		path: path,
+	}
+}
+
+// Load returns the values of the file by their keys, like "database.host".
+func (source *fileSource) Load() (map[string]any, error) {
+	content, err := os.ReadFile(source.path)
+	if err != nil {
+		return nil, fmt.Errorf("can't load configuration file %v: %w", source.path, err)
+	}
+
+	var values map[string]any
+	if err = yaml.Unmarshal(content, &values); err != nil {
+		return nil, fmt.Errorf("can't parse configuration file %v: %w", source.path, err)
+	}
+
+	return di.MapSource(values).Load()
+}
@@ -0,0 +1,51 @@
+package diyaml
+
+import (
+	di "github.com/ashkanabd/go-di"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type DatabaseOptions struct {
+	Host     string
+	Port     int
+	Replicas []string
+}
+
+func TestFileSource(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	assert.Nil(t, os.WriteFile(path, []byte(`
+database:
+  host: db
+  port: 5433
+  replicas: [r1, r2]
+`), 0o600))
+
+	collection := di.InitServiceCollection()
+	assert.Nil(t, collection.AddConfigSource(FileSource(path)))
+	assert.Nil(t, di.AddOptions[DatabaseOptions](collection, "database"))
+	collection.Lock()
+
+	scope, err := collection.CreateScope()
+	assert.Nil(t, err)
+	defer scope.Close()
+
+	options, err := di.GetService[DatabaseOptions](scope)
+	assert.Nil(t, err)
+	assert.Equal(t, DatabaseOptions{Host: "db", Port: 5433, Replicas: []string{"r1", "r2"}}, options)
+}
+
+func TestFileSourceErrors(t *testing.T) {
+	_, err := FileSource(filepath.Join(t.TempDir(), "missing.yaml")).Load()
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "can't load configuration file")
+
+	path := filepath.Join(t.TempDir(), "invalid.yaml")
+	assert.Nil(t, os.WriteFile(path, []byte("database: [unclosed"), 0o600))
+
+	_, err = FileSource(path).Load()
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "can't parse configuration file")
+}
@@ -4,13 +4,13 @@ go 1.18
 
 require (
 	github.com/ashkanabd/go-di v0.0.0-20220826085616-e156203e47c7
+	github.com/ashkanabd/go-di/difiber v0.0.0-00010101000000-000000000000
 	github.com/gofiber/fiber/v2 v2.36.0
 )
 
 require (
 	github.com/andybalholm/brotli v1.0.4 // indirect
 	github.com/klauspost/compress v1.15.0 // indirect
-	github.com/sirupsen/logrus v1.9.0 // indirect
 	github.com/valyala/bytebufferpool v1.0.0 // indirect
 	github.com/valyala/fasthttp v1.38.0 // indirect
 	github.com/valyala/tcplisten v1.0.0 // indirect
@@ -18,3 +18,5 @@ require (
 )
 
 replace github.com/ashkanabd/go-di => ../../
+
+replace github.com/ashkanabd/go-di/difiber => ../../difiber
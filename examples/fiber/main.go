@@ -3,6 +3,7 @@ package main
 import (
 	"fmt"
 	di "github.com/ashkanabd/go-di"
+	"github.com/ashkanabd/go-di/difiber"
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/logger"
 	"strconv"
@@ -40,8 +41,8 @@ func main() {
 	fiberApp := fiber.New()
 	fiberApp.Use(logger.New())
 
-	// Register create scope middleware in fiber
-	fiberApp.Use(CreateScopeMiddleware)
+	// Register difiber middleware to create scope on incoming requests
+	fiberApp.Use(difiber.Middleware(collection))
 
 	// Register routes
 	fiberApp.Get("/testSingleton", handleSingleton)
@@ -51,25 +52,9 @@ func main() {
 	fiberApp.Listen(":3000")
 }
 
-func CreateScopeMiddleware(c *fiber.Ctx) error {
-	// Create scope before request
-	scope, err := collection.CreateScope()
-
-	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).
-			SendString(fmt.Sprintf("Can't create scope: %v\n", err.Error()))
-	}
-
-	c.Locals("scope", scope)
-	return c.Next()
-}
-
 func handleSingleton(ctx *fiber.Ctx) error {
-	// Get scope from fiber locals
-	scope := ctx.Locals("scope").(*di.Scope)
-
 	// Request a singleton service
-	singletonService, _ := di.GetService[*SingletonService](scope)
+	singletonService, _ := difiber.Get[*SingletonService](ctx)
 
 	ctx.WriteString(fmt.Sprintf("Pre value was: %v\n", singletonService.counter))
 
@@ -83,7 +68,7 @@ func handleSingleton(ctx *fiber.Ctx) error {
 	singletonService.counter += value
 
 	// Request singleton service again
-	singletonService, _ = di.GetService[*SingletonService](scope)
+	singletonService, _ = difiber.Get[*SingletonService](ctx)
 
 	ctx.WriteString(fmt.Sprintf("New value: %v\n", singletonService.counter))
 
@@ -91,11 +76,8 @@ func handleSingleton(ctx *fiber.Ctx) error {
 }
 
 func handleScoped(ctx *fiber.Ctx) error {
-	// Get scope from fiber locals
-	scope := ctx.Locals("scope").(*di.Scope)
-
 	// Request a scoped service
-	scopedService, _ := di.GetService[*ScopedService](scope)
+	scopedService, _ := difiber.Get[*ScopedService](ctx)
 
 	ctx.WriteString(fmt.Sprintf("Pre value was: %v\n", scopedService.counter))
 
@@ -109,7 +91,7 @@ func handleScoped(ctx *fiber.Ctx) error {
 	scopedService.counter += value
 
 	// Request scoped service again
-	scopedService, _ = di.GetService[*ScopedService](scope)
+	scopedService, _ = difiber.Get[*ScopedService](ctx)
 
 	ctx.WriteString(fmt.Sprintf("New value: %v\n", scopedService.counter))
 
@@ -117,11 +99,8 @@ func handleScoped(ctx *fiber.Ctx) error {
 }
 
 func handleTransient(ctx *fiber.Ctx) error {
-	// Get scope from fiber locals
-	scope := ctx.Locals("scope").(*di.Scope)
-
 	// Request a transient service
-	transientService, _ := di.GetService[*TransientService](scope)
+	transientService, _ := difiber.Get[*TransientService](ctx)
 
 	ctx.WriteString(fmt.Sprintf("Pre value was: %v\n", transientService.counter))
 
@@ -135,7 +114,7 @@ func handleTransient(ctx *fiber.Ctx) error {
 	transientService.counter += value
 
 	// Request transient service again
-	transientService, _ = di.GetService[*TransientService](scope)
+	transientService, _ = difiber.Get[*TransientService](ctx)
 
 	ctx.WriteString(fmt.Sprintf("New value: %v\n", transientService.counter))
 
@@ -4,6 +4,7 @@ go 1.18
 
 require (
 	github.com/ashkanabd/go-di v0.0.0-20220826085616-e156203e47c7
+	github.com/ashkanabd/go-di/digin v0.0.0-00010101000000-000000000000
 	github.com/gin-gonic/gin v1.8.1
 )
 
@@ -19,7 +20,6 @@ require (
 	github.com/modern-go/concurrent v0.0.0-20180228061459-e0a39a4cb421 // indirect
 	github.com/modern-go/reflect2 v1.0.2 // indirect
 	github.com/pelletier/go-toml/v2 v2.0.1 // indirect
-	github.com/sirupsen/logrus v1.9.0 // indirect
 	github.com/ugorji/go/codec v1.2.7 // indirect
 	golang.org/x/crypto v0.0.0-20210711020723-a769d52b0f97 // indirect
 	golang.org/x/net v0.0.0-20210226172049-e18ecbb05110 // indirect
@@ -30,3 +30,5 @@ require (
 )
 
 replace github.com/ashkanabd/go-di => ../../
+
+replace github.com/ashkanabd/go-di/digin => ../../digin
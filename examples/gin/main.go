@@ -3,8 +3,8 @@ package main
 import (
 	"fmt"
 	di "github.com/ashkanabd/go-di"
+	"github.com/ashkanabd/go-di/digin"
 	"github.com/gin-gonic/gin"
-	"net/http"
 	"strconv"
 )
 
@@ -39,8 +39,8 @@ func main() {
 	// Initialize gin
 	r := gin.Default()
 
-	// Register create scope middleware to create scope on incoming requests
-	r.Use(CreateScopeMiddleware)
+	// Register digin middleware to create scope on incoming requests
+	r.Use(digin.Middleware(collection))
 
 	// Register routes on gin
 	r.GET("/testSingleton", handleSingleton)
@@ -50,27 +50,9 @@ func main() {
 	r.Run(":3000")
 }
 
-func CreateScopeMiddleware(c *gin.Context) {
-	// Create scope before request
-	scope, err := collection.CreateScope()
-
-	if err != nil {
-		c.Writer.WriteString(fmt.Sprintf("Can't create scope: %v\n", err.Error()))
-		c.Writer.WriteHeader(http.StatusInternalServerError)
-		return
-	}
-
-	c.Set("scope", scope)
-
-	c.Next()
-}
-
 func handleSingleton(ctx *gin.Context) {
-	// Get scope from gin context
-	scope := ctx.MustGet("scope").(*di.Scope)
-
 	// Request a singleton service
-	singletonService, _ := di.GetService[*SingletonService](scope)
+	singletonService, _ := digin.Get[*SingletonService](ctx)
 
 	ctx.Writer.WriteString(fmt.Sprintf("Pre value was: %v\n", singletonService.counter))
 
@@ -84,17 +66,14 @@ func handleSingleton(ctx *gin.Context) {
 	singletonService.counter += value
 
 	// Request singleton service again
-	singletonService, _ = di.GetService[*SingletonService](scope)
+	singletonService, _ = digin.Get[*SingletonService](ctx)
 
 	ctx.Writer.WriteString(fmt.Sprintf("New value: %v\n", singletonService.counter))
 }
 
 func handleScoped(ctx *gin.Context) {
-	// Get scope from gin context
-	scope := ctx.MustGet("scope").(*di.Scope)
-
 	// Request a scoped service
-	scopedService, _ := di.GetService[*ScopedService](scope)
+	scopedService, _ := digin.Get[*ScopedService](ctx)
 
 	ctx.Writer.WriteString(fmt.Sprintf("Pre value was: %v\n", scopedService.counter))
 
@@ -108,17 +87,14 @@ func handleScoped(ctx *gin.Context) {
 	scopedService.counter += value
 
 	// Request scoped service again
-	scopedService, _ = di.GetService[*ScopedService](scope)
+	scopedService, _ = digin.Get[*ScopedService](ctx)
 
 	ctx.Writer.WriteString(fmt.Sprintf("New value: %v\n", scopedService.counter))
 }
 
 func handleTransient(ctx *gin.Context) {
-	// Get scope from gin context
-	scope := ctx.MustGet("scope").(*di.Scope)
-
 	// Request a transient service
-	transientService, _ := di.GetService[*TransientService](scope)
+	transientService, _ := digin.Get[*TransientService](ctx)
 
 	ctx.Writer.WriteString(fmt.Sprintf("Pre value was: %v\n", transientService.counter))
 
@@ -132,7 +108,7 @@ func handleTransient(ctx *gin.Context) {
 	transientService.counter += value
 
 	// Request transient service again
-	transientService, _ = di.GetService[*TransientService](scope)
+	transientService, _ = digin.Get[*TransientService](ctx)
 
 	ctx.Writer.WriteString(fmt.Sprintf("New value: %v\n", transientService.counter))
 }
@@ -4,9 +4,4 @@ go 1.18
 
 require github.com/ashkanabd/go-di v0.0.0-20220826085616-e156203e47c7
 
-require (
-	github.com/sirupsen/logrus v1.9.0 // indirect
-	golang.org/x/sys v0.0.0-20220825204002-c680a09ffe64 // indirect
-)
-
 replace github.com/ashkanabd/go-di => ../../
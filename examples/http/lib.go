@@ -1,7 +1,6 @@
 package main
 
 import (
-	"io"
 	"net/http"
 )
 
@@ -24,8 +23,3 @@ func getFromQuery(r *http.Request, key string, defaultValue string) string {
 
 	return r.URL.Query().Get(key)
 }
-
-func writeInternalError(w http.ResponseWriter, msg string) {
-	w.WriteHeader(http.StatusInternalServerError)
-	io.WriteString(w, msg)
-}
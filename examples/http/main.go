@@ -3,6 +3,7 @@ package main
 import (
 	"fmt"
 	di "github.com/ashkanabd/go-di"
+	"github.com/ashkanabd/go-di/dihttp"
 	"io"
 	"net/http"
 	"strconv"
@@ -33,46 +34,23 @@ func main() {
 	collection.Lock()
 
 	// Registering routes and handle functions
-	http.HandleFunc("/testSingleton", func(w http.ResponseWriter, r *http.Request) {
-		scope, err := collection.CreateScope()
-		if err != nil {
-			writeInternalError(w, fmt.Sprintf("Can't create scope: %v", err.Error()))
-			return
-		}
-
-		handleTestSingleton(w, r, scope)
-	})
-	http.HandleFunc("/testScoped", func(w http.ResponseWriter, r *http.Request) {
-		scope, err := collection.CreateScope()
-		if err != nil {
-			writeInternalError(w, fmt.Sprintf("Can't create scope: %v", err.Error()))
-			return
-		}
-
-		handleTestScoped(w, r, scope)
-	})
-	http.HandleFunc("/testTransient", func(w http.ResponseWriter, r *http.Request) {
-		scope, err := collection.CreateScope()
-		if err != nil {
-			writeInternalError(w, fmt.Sprintf("Can't create scope: %v", err.Error()))
-			return
-		}
-
-		handleTestTransient(w, r, scope)
-	})
+	mux := http.NewServeMux()
+	mux.HandleFunc("/testSingleton", handleTestSingleton)
+	mux.HandleFunc("/testScoped", handleTestScoped)
+	mux.HandleFunc("/testTransient", handleTestTransient)
 
 	fmt.Println("Staring to listen at port 3000")
 	fmt.Println("Registered routes: ")
 	fmt.Println("/testSingleton")
 	fmt.Println("/testScoped")
 	fmt.Println("/testTransient")
-	// Staring to listen
-	http.ListenAndServe(":3000", nil)
+	// Staring to listen, the middleware creates a scope for every request
+	http.ListenAndServe(":3000", dihttp.Middleware(collection)(mux))
 }
 
-func handleTestSingleton(w http.ResponseWriter, r *http.Request, s *di.Scope) {
+func handleTestSingleton(w http.ResponseWriter, r *http.Request) {
 	// Request a singleton service
-	singletonService, _ := di.GetService[*SingletonService](s)
+	singletonService, _ := dihttp.Get[*SingletonService](r)
 
 	io.WriteString(w, fmt.Sprintf("Pre value was: %v\n", singletonService.counter))
 
@@ -86,14 +64,14 @@ func handleTestSingleton(w http.ResponseWriter, r *http.Request, s *di.Scope) {
 	singletonService.counter += value
 
 	// Request singleton service again
-	singletonService, _ = di.GetService[*SingletonService](s)
+	singletonService, _ = dihttp.Get[*SingletonService](r)
 
 	io.WriteString(w, fmt.Sprintf("New value: %v\n", singletonService.counter))
 }
 
-func handleTestScoped(w http.ResponseWriter, r *http.Request, s *di.Scope) {
+func handleTestScoped(w http.ResponseWriter, r *http.Request) {
 	// Request a scoped service
-	scopedService, _ := di.GetService[*ScopedService](s)
+	scopedService, _ := dihttp.Get[*ScopedService](r)
 
 	io.WriteString(w, fmt.Sprintf("Pre value was: %v\n", scopedService.counter))
 
@@ -107,14 +85,14 @@ func handleTestScoped(w http.ResponseWriter, r *http.Request, s *di.Scope) {
 	scopedService.counter += value
 
 	// Request scoped service again
-	scopedService, _ = di.GetService[*ScopedService](s)
+	scopedService, _ = dihttp.Get[*ScopedService](r)
 
 	io.WriteString(w, fmt.Sprintf("New value: %v\n", scopedService.counter))
 }
 
-func handleTestTransient(w http.ResponseWriter, r *http.Request, s *di.Scope) {
+func handleTestTransient(w http.ResponseWriter, r *http.Request) {
 	// Request a transient service
-	transientService, _ := di.GetService[*TransientService](s)
+	transientService, _ := dihttp.Get[*TransientService](r)
 
 	io.WriteString(w, fmt.Sprintf("Pre value was: %v\n", transientService.counter))
 
@@ -128,7 +106,7 @@ func handleTestTransient(w http.ResponseWriter, r *http.Request, s *di.Scope) {
 	transientService.counter += value
 
 	// Request transient service again
-	transientService, _ = di.GetService[*TransientService](s)
+	transientService, _ = dihttp.Get[*TransientService](r)
 
 	io.WriteString(w, fmt.Sprintf("New value: %v\n", transientService.counter))
 }
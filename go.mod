@@ -2,14 +2,10 @@ module github.com/ashkanabd/go-di
 
 go 1.18
 
-require (
-	github.com/sirupsen/logrus v1.9.0
-	github.com/stretchr/testify v1.8.0
-)
+require github.com/stretchr/testify v1.8.0
 
 require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
-	golang.org/x/sys v0.0.0-20220825204002-c680a09ffe64 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
@@ -0,0 +1,214 @@
+package dependency_injection
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Graph is the dependency graph of the services which are registered in a ServiceCollection.
+type Graph struct {
+	// Registered services and the services that they depend on.
+	Nodes []GraphNode `json:"nodes"`
+	// Dependencies between services.
+	Edges []GraphEdge `json:"edges"`
+}
+
+// GraphNode is a service in the dependency graph.
+type GraphNode struct {
+	// Type that the service is registered with, it's the key of the node in the graph.
+	Type string `json:"type"`
+	// Lifetime of the service, services which are not registered but other services depend on have "unregistered" lifetime.
+	Lifetime string `json:"lifetime"`
+	// Name of the module that registered the service.
+	Module string `json:"module,omitempty"`
+	// Indicates that the service is inherited from a parent collection.
+	Inherited bool `json:"inherited,omitempty"`
+}
+
+// GraphEdge is a dependency of a service to an other service in the dependency graph.
+type GraphEdge struct {
+	// Type of the service which depends on the other service.
+	From string `json:"from"`
+	// Type of the service which is the dependency.
+	To string `json:"to"`
+	// Indicates that the dependency is declared by DependsOn while registering the service.
+	Declared bool `json:"declared"`
+	// Indicates that the dependency is requested by the provider of the service at runtime.
+	Recorded bool `json:"recorded"`
+}
+
+// recordDependency records that provider of the service requested given type.
+func (serviceType *ServiceType) recordDependency(t reflect.Type) {
+	serviceType.dependencyMutex.Lock()
+	defer serviceType.dependencyMutex.Unlock()
+
+	if serviceType.recordedDependencies == nil {
+		serviceType.recordedDependencies = make(map[reflect.Type]bool)
+	}
+	serviceType.recordedDependencies[t] = true
+}
+
+// Graph returns the dependency graph of all services that can be requested from the collection, including inherited ones.
+// Dependencies are either declared with DependsOn or recorded when providers request services at runtime.
+func (collection *ServiceCollection) Graph() *Graph {
+	graph := &Graph{
+		Nodes: make([]GraphNode, 0),
+		Edges: make([]GraphEdge, 0),
+	}
+
+	nodes := make(map[reflect.Type]bool)
+	edges := make(map[[2]reflect.Type]*GraphEdge)
+	dependencies := make([]reflect.Type, 0)
+
+	for current := collection; current != nil; current = current.parent {
+		for t, serviceType := range current.registrations() {
+			if nodes[t] {
+				// Service is overridden in a child collection.
+				continue
+			}
+			nodes[t] = true
+
+			graph.Nodes = append(graph.Nodes, GraphNode{
+				Type:      t.String(),
+				Lifetime:  lifetimeName(serviceType.lifetime),
+				Module:    serviceType.module,
+				Inherited: current != collection,
+			})
+
+			edge := func(dependency reflect.Type) *GraphEdge {
+				key := [2]reflect.Type{t, dependency}
+				if _, exists := edges[key]; !exists {
+					edges[key] = &GraphEdge{From: t.String(), To: dependency.String()}
+					dependencies = append(dependencies, dependency)
+				}
+				return edges[key]
+			}
+
+			for _, dependency := range serviceType.declaredDependencies {
+				edge(dependency).Declared = true
+			}
+
+			serviceType.dependencyMutex.Lock()
+			for dependency := range serviceType.recordedDependencies {
+				edge(dependency).Recorded = true
+			}
+			serviceType.dependencyMutex.Unlock()
+		}
+	}
+
+	for _, dependency := range dependencies {
+		if !nodes[dependency] {
+			nodes[dependency] = true
+			graph.Nodes = append(graph.Nodes, GraphNode{
+				Type:     dependency.String(),
+				Lifetime: "unregistered",
+			})
+		}
+	}
+
+	for _, edge := range edges {
+		graph.Edges = append(graph.Edges, *edge)
+	}
+
+	sort.Slice(graph.Nodes, func(i, j int) bool {
+		return graph.Nodes[i].Type < graph.Nodes[j].Type
+	})
+	sort.Slice(graph.Edges, func(i, j int) bool {
+		if graph.Edges[i].From != graph.Edges[j].From {
+			return graph.Edges[i].From < graph.Edges[j].From
+		}
+		return graph.Edges[i].To < graph.Edges[j].To
+	})
+
+	return graph
+}
+
+// nodeIDs returns identifiers of the nodes that are safe to use in DOT and Mermaid.
+func (graph *Graph) nodeIDs() map[string]string {
+	ids := make(map[string]string, len(graph.Nodes))
+	for i, node := range graph.Nodes {
+		ids[node.Type] = fmt.Sprintf("n%v", i)
+	}
+
+	return ids
+}
+
+// nodeLabel returns the label of a node in DOT and Mermaid.
+func nodeLabel(node GraphNode, separator string) string {
+	label := node.Type + separator + node.Lifetime
+	if node.Module != "" {
+		label += separator + "module: " + node.Module
+	}
+
+	return label
+}
+
+// WriteDOT encodes the graph in Graphviz DOT format.
+// Recorded dependencies which are not declared are drawn with dashed edges.
+func (graph *Graph) WriteDOT(w io.Writer) error {
+	ids := graph.nodeIDs()
+
+	builder := strings.Builder{}
+	builder.WriteString("digraph services {\n")
+	builder.WriteString("\tnode [shape=box];\n")
+
+	for _, node := range graph.Nodes {
+		style := ""
+		if node.Lifetime == "unregistered" {
+			style = ", color=red"
+		}
+		builder.WriteString(fmt.Sprintf("\t%v [label=%q%v];\n", ids[node.Type], nodeLabel(node, "\n"), style))
+	}
+
+	for _, edge := range graph.Edges {
+		style := ""
+		if !edge.Declared {
+			style = " [style=dashed]"
+		}
+		builder.WriteString(fmt.Sprintf("\t%v -> %v%v;\n", ids[edge.From], ids[edge.To], style))
+	}
+
+	builder.WriteString("}\n")
+
+	_, err := io.WriteString(w, builder.String())
+
+	return err
+}
+
+// WriteMermaid encodes the graph as a Mermaid flowchart.
+// Recorded dependencies which are not declared are drawn with dotted edges.
+func (graph *Graph) WriteMermaid(w io.Writer) error {
+	ids := graph.nodeIDs()
+
+	builder := strings.Builder{}
+	builder.WriteString("graph LR\n")
+
+	for _, node := range graph.Nodes {
+		label := strings.ReplaceAll(nodeLabel(node, "<br/>"), "\"", "#quot;")
+		builder.WriteString(fmt.Sprintf("\t%v[\"%v\"]\n", ids[node.Type], label))
+	}
+
+	for _, edge := range graph.Edges {
+		arrow := "-->"
+		if !edge.Declared {
+			arrow = "-.->"
+		}
+		builder.WriteString(fmt.Sprintf("\t%v %v %v\n", ids[edge.From], arrow, ids[edge.To]))
+	}
+
+	_, err := io.WriteString(w, builder.String())
+
+	return err
+}
+
+// WriteJSON encodes the graph as indented JSON.
+func (graph *Graph) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(graph)
+}
@@ -0,0 +1,117 @@
+package dependency_injection
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+// initGraphCollection initialize a collection with a repository that declares a dependency on config
+// and requests config and a not registered service at runtime.
+func initGraphCollection(t *testing.T) *ServiceCollection {
+	collection := InitServiceCollection()
+
+	err := collection.Install(NewModule("config", func(collection *ServiceCollection) error {
+		return AddSingleton[TestConfig](collection, func(s *Scope) any {
+			return TestConfig{tenant: "default"}
+		})
+	}))
+	assert.Nil(t, err)
+
+	err = AddScoped[*TestRepository](collection, func(s *Scope) any {
+		config, _ := GetService[TestConfig](s)
+		_, _ = GetService[TestType](s)
+		return &TestRepository{config: config}
+	}, DependsOn[TestConfig]())
+	assert.Nil(t, err)
+
+	collection.Lock()
+
+	return collection
+}
+
+func TestGraph(t *testing.T) {
+	collection := initGraphCollection(t)
+
+	// Only declared dependencies are available before resolution
+	graph := collection.Graph()
+	assert.Equal(t, []GraphNode{
+		{Type: "*dependency_injection.TestRepository", Lifetime: "scoped"},
+		{Type: "dependency_injection.TestConfig", Lifetime: "singleton", Module: "config"},
+	}, graph.Nodes)
+	assert.Equal(t, []GraphEdge{
+		{From: "*dependency_injection.TestRepository", To: "dependency_injection.TestConfig", Declared: true},
+	}, graph.Edges)
+
+	scope, err := collection.CreateScope()
+	assert.Nil(t, err)
+	_, err = GetService[*TestRepository](scope)
+	assert.Nil(t, err)
+
+	// Recorded dependencies are available after resolution
+	graph = collection.Graph()
+	assert.Equal(t, []GraphNode{
+		{Type: "*dependency_injection.TestRepository", Lifetime: "scoped"},
+		{Type: "dependency_injection.TestConfig", Lifetime: "singleton", Module: "config"},
+		{Type: "dependency_injection.TestType", Lifetime: "unregistered"},
+	}, graph.Nodes)
+	assert.Equal(t, []GraphEdge{
+		{From: "*dependency_injection.TestRepository", To: "dependency_injection.TestConfig", Declared: true, Recorded: true},
+		{From: "*dependency_injection.TestRepository", To: "dependency_injection.TestType", Recorded: true},
+	}, graph.Edges)
+
+	// Child collections show inherited services
+	child := collection.CreateChild()
+	err = AddSingleton[TestConfig](child, func(s *Scope) any {
+		return TestConfig{tenant: "tenant-1"}
+	})
+	assert.Nil(t, err)
+
+	childGraph := child.Graph()
+	assert.Equal(t, []GraphNode{
+		{Type: "*dependency_injection.TestRepository", Lifetime: "scoped", Inherited: true},
+		{Type: "dependency_injection.TestConfig", Lifetime: "singleton"},
+		{Type: "dependency_injection.TestType", Lifetime: "unregistered"},
+	}, childGraph.Nodes)
+}
+
+func TestGraphEncoders(t *testing.T) {
+	collection := initGraphCollection(t)
+
+	scope, err := collection.CreateScope()
+	assert.Nil(t, err)
+	_, err = GetService[*TestRepository](scope)
+	assert.Nil(t, err)
+
+	graph := collection.Graph()
+
+	dot := bytes.Buffer{}
+	assert.Nil(t, graph.WriteDOT(&dot))
+	assert.Equal(t, `digraph services {
+	node [shape=box];
+	n0 [label="*dependency_injection.TestRepository\nscoped"];
+	n1 [label="dependency_injection.TestConfig\nsingleton\nmodule: config"];
+	n2 [label="dependency_injection.TestType\nunregistered", color=red];
+	n0 -> n1;
+	n0 -> n2 [style=dashed];
+}
+`, dot.String())
+
+	mermaid := bytes.Buffer{}
+	assert.Nil(t, graph.WriteMermaid(&mermaid))
+	assert.Equal(t, `graph LR
+	n0["*dependency_injection.TestRepository<br/>scoped"]
+	n1["dependency_injection.TestConfig<br/>singleton<br/>module: config"]
+	n2["dependency_injection.TestType<br/>unregistered"]
+	n0 --> n1
+	n0 -.-> n2
+`, mermaid.String())
+
+	encoded := bytes.Buffer{}
+	assert.Nil(t, graph.WriteJSON(&encoded))
+
+	decoded := Graph{}
+	assert.Nil(t, json.Unmarshal(encoded.Bytes(), &decoded))
+	assert.Equal(t, *graph, decoded)
+}
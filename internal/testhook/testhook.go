@@ -0,0 +1,16 @@
+// Package testhook gives ditest package access to the internals of collections which aren't part of the public API.
+// Its functions are set by the root package when it's initialized.
+package testhook
+
+import (
+	"reflect"
+)
+
+// Clone returns a clone of given *di.ServiceCollection with a copy of its registrations,
+// the clone is locked if the collection is locked.
+var Clone func(collection any) any
+
+// Replace replaces the provider of the registered service of given type in given *di.ServiceCollection with
+// given func(s *di.Scope) any, even if the collection is locked, and returns a function that restores the previous
+// registration.
+var Replace func(collection any, serviceType reflect.Type, provider any) (restore func(), err error)
@@ -2,6 +2,9 @@ package dependency_injection
 
 import (
 	"reflect"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
 // Lifetimes
@@ -15,14 +18,102 @@ const (
 	SCOPED
 	// TRANSIENT lifetime represent to the services that will initialize whenever requested.
 	TRANSIENT
+	// POOLED lifetime represent to the services that will be borrowed from an object pool of the service
+	// when a scope requests them and will be returned to the pool when the scope closes.
+	// Same instance will be retrieved if the same scope requests it again.
+	POOLED
 )
 
 // ServiceType used to store the configuration of the services in ServiceCollection
 type ServiceType struct {
+	// Reflect type that the service is registered with.
+	reflectType reflect.Type
+	// Dense integer ID of the reflect type, it indexes scope pools.
+	id       int
 	lifetime int
 	provider func(s *Scope) any
+	// The ServiceCollection that registered the service, singleton instances are stored in its object pool.
+	collection *ServiceCollection
+	// Name of the module that registered the service, empty for services registered outside of modules.
+	module string
+	// Maximum number of idle instances that object pool of a pooled service keeps.
+	poolSize int
+	// Object pool of a pooled service.
+	pool *objectPool
+	// Resets an instance of a pooled service before returning it to the object pool.
+	reset func(value any)
+	// Time to live of a singleton service, zero means the singleton never expires.
+	ttl time.Duration
+	// Initialized instance of a singleton service, it's read without locks by resolutions of singletons without ttl.
+	instance atomic.Value
+	// Expiration time of the current instance of a singleton service with ttl.
+	expiresAt time.Time
+	// Indicates that a new instance of an expired singleton service is being initialized.
+	refreshing bool
+	// Indicates that the current instance of a singleton service is shared by the collection that the collection of
+	// the service is cloned from, so the collection of the service must not dispose it.
+	borrowed bool
+	// A mutex to initialize a singleton service only once while it's requested concurrently.
+	buildMutex sync.Mutex
+	// Disposes an instance of a service when it's replaced or invalidated.
+	dispose func(value any)
+	// Retry policy of the service for the times that its provider fails.
+	retryPolicy *RetryPolicy
+	// Dependencies of the service which are declared while registering the service.
+	declaredDependencies []reflect.Type
+	// Dependencies of the service which are requested by its provider at runtime.
+	recordedDependencies map[reflect.Type]bool
+	// A mutex to handle data race while recording dependencies of the service.
+	dependencyMutex sync.Mutex
 }
 
+// ServiceOption configures optional behaviours of a service while registering it in ServiceCollection.
+type ServiceOption func(serviceType *ServiceType)
+
+// WithPoolSize sets the maximum number of idle instances that object pool of a pooled service keeps.
+// Instances that are returned to a full pool will be evicted.
+func WithPoolSize(size int) ServiceOption {
+	return func(serviceType *ServiceType) {
+		serviceType.poolSize = size
+	}
+}
+
+// WithDispose sets a function to dispose old instances of a singleton service when they get expired or invalidated.
+func WithDispose[T any](dispose func(T)) ServiceOption {
+	return func(serviceType *ServiceType) {
+		serviceType.dispose = func(value any) {
+			dispose(value.(T))
+		}
+	}
+}
+
+// DependsOn declares that the service depends on T, declared dependencies are shown in the dependency graph
+// even before the provider of the service is called.
+func DependsOn[T any]() ServiceOption {
+	return func(serviceType *ServiceType) {
+		serviceType.declaredDependencies = append(serviceType.declaredDependencies, getReflectType[T]())
+	}
+}
+
+// lifetimeName returns the human-readable name of a lifetime.
+func lifetimeName(lifetime int) string {
+	switch lifetime {
+	case SINGLETON:
+		return "singleton"
+	case SCOPED:
+		return "scoped"
+	case TRANSIENT:
+		return "transient"
+	case POOLED:
+		return "pooled"
+	}
+
+	return "unknown"
+}
+
+// now returns current time, tests can replace it to control expiration of services.
+var now = time.Now
+
 // getReflectType returns reflect type of given generic type.
 func getReflectType[T any]() reflect.Type {
 	var t T
@@ -0,0 +1,71 @@
+package dependency_injection
+
+// Logger is used by ServiceCollection to write debug logs about resolving services.
+// Log messages and their fields are built only if Enabled returns true,
+// so a disabled logger costs nothing while resolving services.
+type Logger interface {
+	// Enabled reports whether debug logs will be written.
+	Enabled() bool
+	// Debug writes a debug log with given message and structured fields.
+	Debug(msg string, fields ...Field)
+}
+
+// Field is a structured key-value pair of a log.
+type Field struct {
+	Key   string
+	Value any
+}
+
+// Keys of the structured fields that are written in logs.
+const (
+	// Type of the service that the log is about.
+	FieldService = "service"
+	// Lifetime of the service that the log is about.
+	FieldLifetime = "lifetime"
+	// ID of the scope that requested the service.
+	FieldScopeID = "scope_id"
+)
+
+// nopLogger is a Logger that writes nothing.
+type nopLogger struct{}
+
+// NopLogger returns a Logger that writes nothing, it's the default logger of ServiceCollection.
+func NopLogger() Logger {
+	return nopLogger{}
+}
+
+// Enabled always returns false.
+func (nopLogger) Enabled() bool {
+	return false
+}
+
+// Debug writes nothing.
+func (nopLogger) Debug(string, ...Field) {}
+
+// SetLogger sets the logger that the collection and its scopes use, it can't be changed after locking the collection.
+// Child collections use the logger of their parent at the time of creation.
+func (collection *ServiceCollection) SetLogger(logger Logger) error {
+	if err := collection.checkLock(); err != nil {
+		return err
+	}
+
+	if logger == nil {
+		logger = NopLogger()
+	}
+	collection.logger = logger
+
+	return nil
+}
+
+// debug writes a debug log about a service requested by the scope.
+func (s *Scope) debug(msg string, serviceType *ServiceType) {
+	if !s.collection.logger.Enabled() {
+		return
+	}
+
+	s.collection.logger.Debug(msg,
+		Field{Key: FieldService, Value: serviceType.reflectType.String()},
+		Field{Key: FieldLifetime, Value: lifetimeName(serviceType.lifetime)},
+		Field{Key: FieldScopeID, Value: s.id},
+	)
+}
@@ -0,0 +1,94 @@
+package dependency_injection
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+// testLogger is a Logger that records written logs.
+type testLogger struct {
+	enabled bool
+	logs    []string
+}
+
+func (l *testLogger) Enabled() bool {
+	return l.enabled
+}
+
+func (l *testLogger) Debug(msg string, fields ...Field) {
+	if !l.enabled {
+		panic("debug log is written while logger is disabled")
+	}
+
+	log := msg
+	for _, field := range fields {
+		log += fmt.Sprintf(" %v=%v", field.Key, field.Value)
+	}
+	l.logs = append(l.logs, log)
+}
+
+func TestLogger(t *testing.T) {
+	collection := InitServiceCollection()
+
+	logger := &testLogger{enabled: true}
+	assert.Nil(t, collection.SetLogger(logger))
+
+	err := AddScoped[TestType](collection, func(s *Scope) any {
+		return TestType{}
+	})
+	assert.Nil(t, err)
+
+	collection.Lock()
+
+	assert.Equal(t, fmt.Errorf("service collection is locked, you can't register an other service"), collection.SetLogger(NopLogger()))
+
+	scope, err := collection.CreateScope()
+	assert.Nil(t, err)
+
+	_, err = GetService[TestType](scope)
+	assert.Nil(t, err)
+	_, err = GetService[TestType](scope)
+	assert.Nil(t, err)
+
+	fields := fmt.Sprintf("service=dependency_injection.TestType lifetime=scoped scope_id=%v", scope.ID())
+	assert.Equal(t, []string{
+		"Injecting scoped service " + fields,
+		"Providing scoped value " + fields,
+		"Injecting scoped service " + fields,
+		"Value retrieved from scope pool " + fields,
+	}, logger.logs)
+}
+
+func TestDisabledLogger(t *testing.T) {
+	collection := InitServiceCollection()
+
+	logger := &testLogger{enabled: false}
+	assert.Nil(t, collection.SetLogger(logger))
+
+	err := AddSingleton[TestType](collection, func(s *Scope) any {
+		return TestType{}
+	})
+	assert.Nil(t, err)
+
+	collection.Lock()
+
+	scope, err := collection.CreateScope()
+	assert.Nil(t, err)
+
+	_, err = GetService[TestType](scope)
+	assert.Nil(t, err)
+	assert.Empty(t, logger.logs)
+}
+
+func TestUniqueScopeID(t *testing.T) {
+	collection := InitServiceCollection()
+	collection.Lock()
+
+	scope1, err := collection.CreateScope()
+	assert.Nil(t, err)
+	scope2, err := collection.CreateScope()
+	assert.Nil(t, err)
+
+	assert.NotEqual(t, scope1.ID(), scope2.ID())
+}
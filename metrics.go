@@ -0,0 +1,317 @@
+package dependency_injection
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// defaultLatencyBuckets are upper bounds of provider latency histogram buckets in seconds.
+var defaultLatencyBuckets = []float64{0.0001, 0.0005, 0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1, 5}
+
+// Metrics is a snapshot of the metrics of a ServiceCollection.
+type Metrics struct {
+	// Metrics of the services that are requested at least once, sorted by service type.
+	Services []ServiceMetrics
+	// Number of scopes which are created by CreateScope and are not closed yet.
+	LiveScopes int64
+	// Number of initialized singleton services which are kept in the singleton pool.
+	Singletons int
+	// Statistics of object pools of pooled services by service type.
+	Pools map[string]PoolStats
+}
+
+// ServiceMetrics are the metrics of resolutions of a service.
+type ServiceMetrics struct {
+	// Type of the service.
+	Service string
+	// Lifetime of the service.
+	Lifetime string
+	// Number of times that the service is requested.
+	Resolutions uint64
+	// Number of resolutions that retrieved the service from singleton, scope or object pools.
+	CacheHits uint64
+	// Number of resolutions that failed.
+	Failures uint64
+	// Latency of calls of the provider of the service.
+	ProviderLatency Histogram
+}
+
+// CacheHitRatio returns the ratio of the resolutions that retrieved the service from a pool.
+func (metrics ServiceMetrics) CacheHitRatio() float64 {
+	if metrics.Resolutions == 0 {
+		return 0
+	}
+
+	return float64(metrics.CacheHits) / float64(metrics.Resolutions)
+}
+
+// Histogram is a snapshot of a latency histogram.
+type Histogram struct {
+	// Upper bounds of the buckets in seconds.
+	Buckets []float64
+	// Number of observations in each bucket, observations greater than the last bucket are only counted in Count.
+	Counts []uint64
+	// Number of all observations.
+	Count uint64
+	// Sum of all observations in seconds.
+	Sum float64
+}
+
+// CacheHitRatio returns the ratio of resolutions of the services with given lifetime that retrieved the service from a pool.
+func (metrics Metrics) CacheHitRatio(lifetime int) float64 {
+	resolutions, hits := uint64(0), uint64(0)
+	for _, service := range metrics.Services {
+		if service.Lifetime == lifetimeName(lifetime) {
+			resolutions += service.Resolutions
+			hits += service.CacheHits
+		}
+	}
+
+	if resolutions == 0 {
+		return 0
+	}
+
+	return float64(hits) / float64(resolutions)
+}
+
+// EnableMetrics starts collecting metrics of resolutions, it can't be enabled after locking the collection.
+// Child collections which are created after enabling metrics share the metrics of their parent.
+func (collection *ServiceCollection) EnableMetrics() error {
+	if err := collection.checkLock(); err != nil {
+		return err
+	}
+
+	if collection.metrics != nil {
+		return nil
+	}
+
+	collection.metrics = &metricsObserver{
+		services: make(map[[2]string]*serviceMetrics),
+	}
+	collection.observers = append(collection.observers, collection.metrics)
+
+	return nil
+}
+
+// Metrics returns a snapshot of the metrics of the collection.
+// Resolution metrics are empty if EnableMetrics is not called.
+func (collection *ServiceCollection) Metrics() Metrics {
+	metrics := Metrics{
+		Services:   make([]ServiceMetrics, 0),
+		LiveScopes: atomic.LoadInt64(&collection.liveScopes),
+		Pools:      make(map[string]PoolStats),
+	}
+
+	if collection.metrics != nil {
+		metrics.Services = collection.metrics.snapshot()
+	}
+
+	collection.mutex.RLock()
+	metrics.Singletons = len(collection.singletonServicePool)
+	collection.mutex.RUnlock()
+
+	for current := collection; current != nil; current = current.parent {
+		for t, serviceType := range current.registrations() {
+			if _, exists := metrics.Pools[t.String()]; !exists && serviceType.lifetime == POOLED {
+				metrics.Pools[t.String()] = serviceType.pool.stats()
+			}
+		}
+	}
+
+	return metrics
+}
+
+// metricsObserver is an Observer that collects metrics of resolutions.
+type metricsObserver struct {
+	// Metrics of services by service type and lifetime.
+	services map[[2]string]*serviceMetrics
+	// A mutex to handle data race while adding metrics of new services.
+	mutex sync.RWMutex
+}
+
+// serviceMetrics collects metrics of a service, it's the ResolutionObserver of all resolutions of the service.
+// Counters are the first fields to keep them 64-bit aligned for atomic operations.
+type serviceMetrics struct {
+	resolutions     uint64
+	cacheHits       uint64
+	failures        uint64
+	latencyCount    uint64
+	latencySumNanos uint64
+	latencyCounts   []uint64
+	service         string
+	lifetime        string
+}
+
+// StartResolution returns the metrics of the requested service.
+func (observer *metricsObserver) StartResolution(event ResolutionEvent, _ ResolutionObserver) ResolutionObserver {
+	key := [2]string{event.Service, event.Lifetime}
+
+	observer.mutex.RLock()
+	metrics, exists := observer.services[key]
+	observer.mutex.RUnlock()
+
+	if exists {
+		return metrics
+	}
+
+	observer.mutex.Lock()
+	defer observer.mutex.Unlock()
+
+	if metrics, exists = observer.services[key]; !exists {
+		metrics = &serviceMetrics{
+			service:       event.Service,
+			lifetime:      event.Lifetime,
+			latencyCounts: make([]uint64, len(defaultLatencyBuckets)),
+		}
+		observer.services[key] = metrics
+	}
+
+	return metrics
+}
+
+// snapshot returns metrics of all services sorted by service type.
+func (observer *metricsObserver) snapshot() []ServiceMetrics {
+	observer.mutex.RLock()
+	defer observer.mutex.RUnlock()
+
+	services := make([]ServiceMetrics, 0, len(observer.services))
+	for _, metrics := range observer.services {
+		counts := make([]uint64, len(metrics.latencyCounts))
+		for i := range metrics.latencyCounts {
+			counts[i] = atomic.LoadUint64(&metrics.latencyCounts[i])
+		}
+
+		services = append(services, ServiceMetrics{
+			Service:     metrics.service,
+			Lifetime:    metrics.lifetime,
+			Resolutions: atomic.LoadUint64(&metrics.resolutions),
+			CacheHits:   atomic.LoadUint64(&metrics.cacheHits),
+			Failures:    atomic.LoadUint64(&metrics.failures),
+			ProviderLatency: Histogram{
+				Buckets: defaultLatencyBuckets,
+				Counts:  counts,
+				Count:   atomic.LoadUint64(&metrics.latencyCount),
+				Sum:     time.Duration(atomic.LoadUint64(&metrics.latencySumNanos)).Seconds(),
+			},
+		})
+	}
+
+	sort.Slice(services, func(i, j int) bool {
+		if services[i].Service != services[j].Service {
+			return services[i].Service < services[j].Service
+		}
+		return services[i].Lifetime < services[j].Lifetime
+	})
+
+	return services
+}
+
+func (metrics *serviceMetrics) CacheHit() {
+	atomic.AddUint64(&metrics.cacheHits, 1)
+}
+
+func (metrics *serviceMetrics) ProviderStart() {}
+
+func (metrics *serviceMetrics) ProviderEnd(duration time.Duration, _ error) {
+	seconds := duration.Seconds()
+	for i, bound := range defaultLatencyBuckets {
+		if seconds <= bound {
+			atomic.AddUint64(&metrics.latencyCounts[i], 1)
+			break
+		}
+	}
+
+	atomic.AddUint64(&metrics.latencyCount, 1)
+	atomic.AddUint64(&metrics.latencySumNanos, uint64(duration))
+}
+
+func (metrics *serviceMetrics) End(_ time.Duration, err error) {
+	atomic.AddUint64(&metrics.resolutions, 1)
+	if err != nil {
+		atomic.AddUint64(&metrics.failures, 1)
+	}
+}
+
+// WritePrometheus writes the metrics in Prometheus text exposition format.
+func (metrics Metrics) WritePrometheus(w io.Writer) error {
+	builder := strings.Builder{}
+
+	writeHeader := func(name string, metricType string, help string) {
+		builder.WriteString(fmt.Sprintf("# HELP %v %v\n# TYPE %v %v\n", name, help, name, metricType))
+	}
+	serviceLabels := func(service ServiceMetrics) string {
+		return fmt.Sprintf("service=\"%v\",lifetime=\"%v\"", escapeLabel(service.Service), escapeLabel(service.Lifetime))
+	}
+
+	writeHeader("di_resolutions_total", "counter", "Number of resolutions of services.")
+	for _, service := range metrics.Services {
+		builder.WriteString(fmt.Sprintf("di_resolutions_total{%v} %v\n", serviceLabels(service), service.Resolutions))
+	}
+
+	writeHeader("di_cache_hits_total", "counter", "Number of resolutions that retrieved services from pools.")
+	for _, service := range metrics.Services {
+		builder.WriteString(fmt.Sprintf("di_cache_hits_total{%v} %v\n", serviceLabels(service), service.CacheHits))
+	}
+
+	writeHeader("di_resolution_failures_total", "counter", "Number of failed resolutions of services.")
+	for _, service := range metrics.Services {
+		builder.WriteString(fmt.Sprintf("di_resolution_failures_total{%v} %v\n", serviceLabels(service), service.Failures))
+	}
+
+	writeHeader("di_provider_duration_seconds", "histogram", "Latency of providers of services.")
+	for _, service := range metrics.Services {
+		labels := serviceLabels(service)
+		histogram := service.ProviderLatency
+
+		cumulative := uint64(0)
+		for i, bound := range histogram.Buckets {
+			cumulative += histogram.Counts[i]
+			builder.WriteString(fmt.Sprintf("di_provider_duration_seconds_bucket{%v,le=\"%v\"} %v\n", labels, formatFloat(bound), cumulative))
+		}
+		builder.WriteString(fmt.Sprintf("di_provider_duration_seconds_bucket{%v,le=\"+Inf\"} %v\n", labels, histogram.Count))
+		builder.WriteString(fmt.Sprintf("di_provider_duration_seconds_sum{%v} %v\n", labels, formatFloat(histogram.Sum)))
+		builder.WriteString(fmt.Sprintf("di_provider_duration_seconds_count{%v} %v\n", labels, histogram.Count))
+	}
+
+	writeHeader("di_live_scopes", "gauge", "Number of scopes which are not closed yet.")
+	builder.WriteString(fmt.Sprintf("di_live_scopes %v\n", metrics.LiveScopes))
+
+	writeHeader("di_singletons", "gauge", "Number of initialized singleton services.")
+	builder.WriteString(fmt.Sprintf("di_singletons %v\n", metrics.Singletons))
+
+	pools := make([]string, 0, len(metrics.Pools))
+	for service := range metrics.Pools {
+		pools = append(pools, service)
+	}
+	sort.Strings(pools)
+
+	writeHeader("di_pool_idle", "gauge", "Number of idle instances in object pools of pooled services.")
+	for _, service := range pools {
+		builder.WriteString(fmt.Sprintf("di_pool_idle{service=\"%v\"} %v\n", escapeLabel(service), metrics.Pools[service].Idle))
+	}
+
+	writeHeader("di_pool_evictions_total", "counter", "Number of instances that were evicted from object pools of pooled services.")
+	for _, service := range pools {
+		builder.WriteString(fmt.Sprintf("di_pool_evictions_total{service=\"%v\"} %v\n", escapeLabel(service), metrics.Pools[service].Evictions))
+	}
+
+	_, err := io.WriteString(w, builder.String())
+
+	return err
+}
+
+// escapeLabel escapes a label value in Prometheus text exposition format.
+func escapeLabel(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
+
+// formatFloat formats a float in Prometheus text exposition format.
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
@@ -0,0 +1,134 @@
+package dependency_injection
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestMetrics(t *testing.T) {
+	collection := InitServiceCollection()
+	assert.Nil(t, collection.EnableMetrics())
+
+	err := AddSingleton[TestConfig](collection, func(s *Scope) any {
+		return TestConfig{tenant: "default"}
+	})
+	assert.Nil(t, err)
+	err = AddScoped[TestType](collection, func(s *Scope) any {
+		return errNotReady
+	})
+	assert.Nil(t, err)
+	err = AddPooled[*TestBuffer](collection, func(s *Scope) any {
+		return &TestBuffer{}
+	}, nil, WithPoolSize(4))
+	assert.Nil(t, err)
+
+	collection.Lock()
+
+	scope1, err := collection.CreateScope()
+	assert.Nil(t, err)
+	scope2, err := collection.CreateScope()
+	assert.Nil(t, err)
+
+	for i := 0; i < 4; i++ {
+		_, err = GetService[TestConfig](scope1)
+		assert.Nil(t, err)
+	}
+	_, err = GetService[TestType](scope1)
+	assert.NotNil(t, err)
+	_, err = GetService[*TestBuffer](scope1)
+	assert.Nil(t, err)
+
+	assert.Nil(t, scope1.Close())
+
+	metrics := collection.Metrics()
+	assert.Equal(t, int64(1), metrics.LiveScopes)
+	assert.Equal(t, 1, metrics.Singletons)
+	assert.Equal(t, PoolStats{Misses: 1, Idle: 1, Size: 4}, metrics.Pools["*dependency_injection.TestBuffer"])
+	assert.Len(t, metrics.Services, 3)
+
+	buffer := metrics.Services[0]
+	assert.Equal(t, "*dependency_injection.TestBuffer", buffer.Service)
+	assert.Equal(t, "pooled", buffer.Lifetime)
+	assert.Equal(t, uint64(1), buffer.Resolutions)
+
+	config := metrics.Services[1]
+	assert.Equal(t, "dependency_injection.TestConfig", config.Service)
+	assert.Equal(t, uint64(4), config.Resolutions)
+	assert.Equal(t, uint64(3), config.CacheHits)
+	assert.Equal(t, 0.75, config.CacheHitRatio())
+	assert.Equal(t, uint64(1), config.ProviderLatency.Count)
+	assert.Equal(t, 0.75, metrics.CacheHitRatio(SINGLETON))
+
+	testType := metrics.Services[2]
+	assert.Equal(t, "scoped", testType.Lifetime)
+	assert.Equal(t, uint64(1), testType.Failures)
+	assert.Equal(t, float64(0), metrics.CacheHitRatio(SCOPED))
+
+	assert.Nil(t, scope2.Close())
+	assert.Equal(t, int64(0), collection.Metrics().LiveScopes)
+}
+
+func TestMetricsWritePrometheus(t *testing.T) {
+	metrics := Metrics{
+		Services: []ServiceMetrics{
+			{
+				Service:     "main.Service",
+				Lifetime:    "scoped",
+				Resolutions: 3,
+				CacheHits:   2,
+				Failures:    0,
+				ProviderLatency: Histogram{
+					Buckets: []float64{0.001, 0.01},
+					Counts:  []uint64{1, 0},
+					Count:   1,
+					Sum:     0.0005,
+				},
+			},
+		},
+		LiveScopes: 2,
+		Singletons: 1,
+		Pools: map[string]PoolStats{
+			"*main.Buffer": {Idle: 3, Evictions: 1},
+		},
+	}
+
+	buffer := bytes.Buffer{}
+	assert.Nil(t, metrics.WritePrometheus(&buffer))
+	assert.Equal(t, strings.Join([]string{
+		"# HELP di_resolutions_total Number of resolutions of services.",
+		"# TYPE di_resolutions_total counter",
+		`di_resolutions_total{service="main.Service",lifetime="scoped"} 3`,
+		"# HELP di_cache_hits_total Number of resolutions that retrieved services from pools.",
+		"# TYPE di_cache_hits_total counter",
+		`di_cache_hits_total{service="main.Service",lifetime="scoped"} 2`,
+		"# HELP di_resolution_failures_total Number of failed resolutions of services.",
+		"# TYPE di_resolution_failures_total counter",
+		`di_resolution_failures_total{service="main.Service",lifetime="scoped"} 0`,
+		"# HELP di_provider_duration_seconds Latency of providers of services.",
+		"# TYPE di_provider_duration_seconds histogram",
+		`di_provider_duration_seconds_bucket{service="main.Service",lifetime="scoped",le="0.001"} 1`,
+		`di_provider_duration_seconds_bucket{service="main.Service",lifetime="scoped",le="0.01"} 1`,
+		`di_provider_duration_seconds_bucket{service="main.Service",lifetime="scoped",le="+Inf"} 1`,
+		`di_provider_duration_seconds_sum{service="main.Service",lifetime="scoped"} 0.0005`,
+		`di_provider_duration_seconds_count{service="main.Service",lifetime="scoped"} 1`,
+		"# HELP di_live_scopes Number of scopes which are not closed yet.",
+		"# TYPE di_live_scopes gauge",
+		"di_live_scopes 2",
+		"# HELP di_singletons Number of initialized singleton services.",
+		"# TYPE di_singletons gauge",
+		"di_singletons 1",
+		"# HELP di_pool_idle Number of idle instances in object pools of pooled services.",
+		"# TYPE di_pool_idle gauge",
+		`di_pool_idle{service="*main.Buffer"} 3`,
+		"# HELP di_pool_evictions_total Number of instances that were evicted from object pools of pooled services.",
+		"# TYPE di_pool_evictions_total counter",
+		`di_pool_evictions_total{service="*main.Buffer"} 1`,
+		"",
+	}, "\n"), buffer.String())
+}
+
+func TestEscapePrometheusLabel(t *testing.T) {
+	assert.Equal(t, `a\"b\\c\n`, escapeLabel("a\"b\\c\n"))
+}
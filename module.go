@@ -0,0 +1,129 @@
+package dependency_injection
+
+import (
+	"fmt"
+)
+
+// Module groups registrations of related services into a reusable unit that can be installed in ServiceCollection.
+type Module interface {
+	// Name of the module, modules with the same name will be installed only once in a collection.
+	Name() string
+	// Dependencies returns the modules that have to be installed before this module.
+	Dependencies() []Module
+	// Register registers services of the module in given collection.
+	Register(collection *ServiceCollection) error
+}
+
+// module is a Module which is defined by NewModule.
+type module struct {
+	name         string
+	register     func(collection *ServiceCollection) error
+	dependencies []Module
+}
+
+// NewModule defines a module with given name, register function and dependencies.
+func NewModule(name string, register func(collection *ServiceCollection) error, dependencies ...Module) Module {
+	return &module{
+		name:         name,
+		register:     register,
+		dependencies: dependencies,
+	}
+}
+
+// Name of the module.
+func (m *module) Name() string {
+	return m.name
+}
+
+// Dependencies of the module.
+func (m *module) Dependencies() []Module {
+	return m.dependencies
+}
+
+// Register registers services of the module.
+func (m *module) Register(collection *ServiceCollection) error {
+	return m.register(collection)
+}
+
+// Install installs given modules and their dependencies in the collection.
+// Dependencies of each module are installed before the module and modules that are already installed will be skipped.
+func (collection *ServiceCollection) Install(modules ...Module) error {
+	if err := collection.checkLock(); err != nil {
+		return err
+	}
+
+	ordered := make([]Module, 0, len(modules))
+	visiting := make(map[string]bool)
+	visited := make(map[string]bool)
+
+	var visit func(m Module) error
+	visit = func(m Module) error {
+		name := m.Name()
+		if visited[name] || collection.installedModules[name] {
+			return nil
+		}
+		if visiting[name] {
+			return fmt.Errorf("module %v has a circular dependency", name)
+		}
+
+		visiting[name] = true
+		for _, dependency := range m.Dependencies() {
+			if err := visit(dependency); err != nil {
+				return err
+			}
+		}
+		visiting[name] = false
+		visited[name] = true
+
+		ordered = append(ordered, m)
+
+		return nil
+	}
+
+	for _, m := range modules {
+		if err := visit(m); err != nil {
+			return err
+		}
+	}
+
+	for _, m := range ordered {
+		if err := collection.installModule(m); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// installModule registers services of a module and marks registered services with the module name.
+func (collection *ServiceCollection) installModule(m Module) error {
+	if collection.logger.Enabled() {
+		collection.logger.Debug("Installing module", Field{Key: "module", Value: m.Name()})
+	}
+
+	collection.installingModule = m.Name()
+	err := m.Register(collection)
+	collection.installingModule = ""
+
+	if err != nil {
+		return fmt.Errorf("failed to install module %v: %w", m.Name(), err)
+	}
+
+	collection.installedModules[m.Name()] = true
+
+	return nil
+}
+
+// GetServiceModule returns name of the module that registered the service,
+// an empty name will be returned for services which are registered outside of modules.
+func GetServiceModule[T any](collection *ServiceCollection) (string, error) {
+	reflectType := getReflectType[T]()
+
+	serviceType, exists := collection.lookup(reflectType)
+
+	if !exists {
+		return "", fmt.Errorf("service %v is not registered in service collection", reflectType.String())
+	}
+
+	return serviceType.module, nil
+}
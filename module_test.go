@@ -0,0 +1,100 @@
+package dependency_injection
+
+import (
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestInstallModules(t *testing.T) {
+	collection := InitServiceCollection()
+
+	installed := make([]string, 0)
+
+	configModule := NewModule("config", func(collection *ServiceCollection) error {
+		installed = append(installed, "config")
+		return AddSingleton[TestConfig](collection, func(s *Scope) any {
+			return TestConfig{tenant: "default"}
+		})
+	})
+	storageModule := NewModule("storage", func(collection *ServiceCollection) error {
+		installed = append(installed, "storage")
+		return AddScoped[*TestRepository](collection, func(s *Scope) any {
+			config, _ := GetService[TestConfig](s)
+			return &TestRepository{config: config}
+		})
+	}, configModule)
+
+	// Dependencies are installed first and duplicated modules are installed once
+	err := collection.Install(storageModule, configModule, storageModule)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"config", "storage"}, installed)
+
+	// Installed modules are skipped
+	err = collection.Install(configModule)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"config", "storage"}, installed)
+
+	err = AddTransient[TestType](collection, func(s *Scope) any {
+		return TestType{}
+	})
+	assert.Nil(t, err)
+
+	moduleName, err := GetServiceModule[TestConfig](collection)
+	assert.Nil(t, err)
+	assert.Equal(t, "config", moduleName)
+
+	moduleName, err = GetServiceModule[*TestRepository](collection)
+	assert.Nil(t, err)
+	assert.Equal(t, "storage", moduleName)
+
+	moduleName, err = GetServiceModule[TestType](collection)
+	assert.Nil(t, err)
+	assert.Equal(t, "", moduleName)
+
+	collection.Lock()
+
+	scope, err := collection.CreateScope()
+	assert.Nil(t, err)
+
+	repository, err := GetService[*TestRepository](scope)
+	assert.Nil(t, err)
+	assert.Equal(t, "default", repository.config.tenant)
+}
+
+func TestInstallModulesWithCircularDependency(t *testing.T) {
+	collection := InitServiceCollection()
+
+	register := func(collection *ServiceCollection) error {
+		return nil
+	}
+
+	authModule := &module{name: "auth", register: register}
+	sessionModule := &module{name: "session", register: register, dependencies: []Module{authModule}}
+	authModule.dependencies = []Module{sessionModule}
+
+	err := collection.Install(authModule)
+	assert.NotNil(t, err)
+	assert.Equal(t, fmt.Errorf("module auth has a circular dependency"), err)
+}
+
+func TestInstallFailedModule(t *testing.T) {
+	collection := InitServiceCollection()
+
+	errRegister := errors.New("missing configuration")
+
+	err := collection.Install(NewModule("storage", func(collection *ServiceCollection) error {
+		return errRegister
+	}))
+	assert.NotNil(t, err)
+	assert.Equal(t, fmt.Errorf("failed to install module storage: %w", errRegister), err)
+
+	collection.Lock()
+
+	err = collection.Install(NewModule("config", func(collection *ServiceCollection) error {
+		return nil
+	}))
+	assert.NotNil(t, err)
+	assert.Equal(t, fmt.Errorf("service collection is locked, you can't register an other service"), err)
+}
@@ -0,0 +1,237 @@
+package dependency_injection
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// ResolutionEvent describes a request of a service from a scope.
+type ResolutionEvent struct {
+	// Type of the requested service.
+	Service string
+	// Lifetime of the requested service, it's "unregistered" for services which are not registered.
+	Lifetime string
+	// ID of the scope that requested the service.
+	ScopeID uint64
+	// Number of services which are being initialized while this service is requested, zero for requests outside providers.
+	Depth int
+	// Context of the scope that requested the service, see CreateScopeWithContext.
+	Context context.Context
+}
+
+// Observer is notified about every resolution of services in a ServiceCollection.
+type Observer interface {
+	// StartResolution is called when a scope requests a service and returns the observer of this resolution.
+	// Parent is the observer of the resolution whose provider requested this service, nil for requests outside providers.
+	StartResolution(event ResolutionEvent, parent ResolutionObserver) ResolutionObserver
+}
+
+// ResolutionObserver is notified about the steps of a single resolution of a service.
+type ResolutionObserver interface {
+	// CacheHit is called when the service is retrieved from singleton, scope or object pools.
+	CacheHit()
+	// ProviderStart is called before every call of the provider of the service.
+	ProviderStart()
+	// ProviderEnd is called after every call of the provider of the service, err is not nil if the provider failed.
+	ProviderEnd(duration time.Duration, err error)
+	// End is called when the resolution is finished, err is not nil if the resolution failed.
+	End(duration time.Duration, err error)
+}
+
+// AddObserver adds an observer to the collection, it can't be added after locking the collection.
+// Child collections use the observers of their parent at the time of creation.
+func (collection *ServiceCollection) AddObserver(observer Observer) error {
+	if err := collection.checkLock(); err != nil {
+		return err
+	}
+
+	collection.observers = append(collection.observers, observer)
+
+	return nil
+}
+
+// observation is a resolution of a service which is observed by the observers of the collection.
+// All methods are safe to call on nil observation, which is used when the collection has no observers.
+type observation struct {
+	// Observers of the resolution, in the same order as observers of the collection.
+	observers []ResolutionObserver
+	// Start time of the resolution.
+	start time.Time
+	// Start time of the last call of the provider.
+	providerStart time.Time
+}
+
+// startObservation notifies observers of the collection that the scope requested a service.
+func (s *Scope) startObservation(reflectType reflect.Type, serviceType *ServiceType) *observation {
+	observers := s.collection.observers
+	if len(observers) == 0 {
+		return nil
+	}
+
+	event := ResolutionEvent{
+		Service:  reflectType.String(),
+		Lifetime: "unregistered",
+		ScopeID:  s.id,
+		Depth:    s.depth(),
+		Context:  s.Context(),
+	}
+	if serviceType != nil {
+		event.Lifetime = lifetimeName(serviceType.lifetime)
+	}
+
+	o := &observation{
+		observers: make([]ResolutionObserver, len(observers)),
+		start:     time.Now(),
+	}
+	for i, observer := range observers {
+		var parent ResolutionObserver
+		if s.observation != nil && i < len(s.observation.observers) {
+			parent = s.observation.observers[i]
+		}
+		o.observers[i] = observer.StartResolution(event, parent)
+	}
+
+	return o
+}
+
+// cacheHit notifies observers that the service is retrieved from a pool.
+func (o *observation) cacheHit() {
+	if o == nil {
+		return
+	}
+
+	for _, observer := range o.observers {
+		observer.CacheHit()
+	}
+}
+
+// startProvider notifies observers that the provider of the service is called.
+func (o *observation) startProvider() {
+	if o == nil {
+		return
+	}
+
+	o.providerStart = time.Now()
+	for _, observer := range o.observers {
+		observer.ProviderStart()
+	}
+}
+
+// endProvider notifies observers that the provider of the service returned.
+func (o *observation) endProvider(err error) {
+	if o == nil {
+		return
+	}
+
+	duration := time.Since(o.providerStart)
+	for _, observer := range o.observers {
+		observer.ProviderEnd(duration, err)
+	}
+}
+
+// end notifies observers that the resolution is finished.
+func (o *observation) end(err error) {
+	if o == nil {
+		return
+	}
+
+	duration := time.Since(o.start)
+	for _, observer := range o.observers {
+		observer.End(duration, err)
+	}
+}
+
+// Kinds of the events which are recorded by Recorder.
+const (
+	EventResolutionStart = "resolution_start"
+	EventCacheHit        = "cache_hit"
+	EventProviderStart   = "provider_start"
+	EventProviderEnd     = "provider_end"
+	EventResolutionEnd   = "resolution_end"
+)
+
+// RecordedEvent is an event which is recorded by Recorder.
+type RecordedEvent struct {
+	ResolutionEvent
+	// Kind of the event.
+	Kind string
+	// Duration of the provider call or the resolution for end events.
+	Duration time.Duration
+	// Error of the provider call or the resolution for end events.
+	Err error
+}
+
+// Recorder is an Observer that keeps all events in memory, it's useful in tests.
+type Recorder struct {
+	events []RecordedEvent
+	mutex  sync.Mutex
+}
+
+// NewRecorder initialize an in-memory Recorder.
+func NewRecorder() *Recorder {
+	return &Recorder{
+		events: make([]RecordedEvent, 0),
+		mutex:  sync.Mutex{},
+	}
+}
+
+// Events returns a copy of recorded events in the order they happened.
+func (recorder *Recorder) Events() []RecordedEvent {
+	recorder.mutex.Lock()
+	defer recorder.mutex.Unlock()
+
+	events := make([]RecordedEvent, len(recorder.events))
+	copy(events, recorder.events)
+
+	return events
+}
+
+// Reset removes all recorded events.
+func (recorder *Recorder) Reset() {
+	recorder.mutex.Lock()
+	defer recorder.mutex.Unlock()
+
+	recorder.events = recorder.events[:0]
+}
+
+// record appends an event to recorded events.
+func (recorder *Recorder) record(event RecordedEvent) {
+	recorder.mutex.Lock()
+	defer recorder.mutex.Unlock()
+
+	recorder.events = append(recorder.events, event)
+}
+
+// StartResolution records start of a resolution.
+func (recorder *Recorder) StartResolution(event ResolutionEvent, _ ResolutionObserver) ResolutionObserver {
+	recorder.record(RecordedEvent{ResolutionEvent: event, Kind: EventResolutionStart})
+
+	return &recorderResolution{
+		recorder: recorder,
+		event:    event,
+	}
+}
+
+// recorderResolution records the steps of a resolution in a Recorder.
+type recorderResolution struct {
+	recorder *Recorder
+	event    ResolutionEvent
+}
+
+func (r *recorderResolution) CacheHit() {
+	r.recorder.record(RecordedEvent{ResolutionEvent: r.event, Kind: EventCacheHit})
+}
+
+func (r *recorderResolution) ProviderStart() {
+	r.recorder.record(RecordedEvent{ResolutionEvent: r.event, Kind: EventProviderStart})
+}
+
+func (r *recorderResolution) ProviderEnd(duration time.Duration, err error) {
+	r.recorder.record(RecordedEvent{ResolutionEvent: r.event, Kind: EventProviderEnd, Duration: duration, Err: err})
+}
+
+func (r *recorderResolution) End(duration time.Duration, err error) {
+	r.recorder.record(RecordedEvent{ResolutionEvent: r.event, Kind: EventResolutionEnd, Duration: duration, Err: err})
+}
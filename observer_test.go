@@ -0,0 +1,237 @@
+package dependency_injection
+
+import (
+	"context"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+// kinds returns kind, service and depth of recorded events.
+func kinds(events []RecordedEvent) []string {
+	result := make([]string, len(events))
+	for i, event := range events {
+		result[i] = fmt.Sprintf("%v %v %v", event.Kind, event.Service, event.Depth)
+	}
+
+	return result
+}
+
+func TestRecorder(t *testing.T) {
+	collection := InitServiceCollection()
+
+	recorder := NewRecorder()
+	assert.Nil(t, collection.AddObserver(recorder))
+
+	err := AddSingleton[TestConfig](collection, func(s *Scope) any {
+		return TestConfig{tenant: "default"}
+	})
+	assert.Nil(t, err)
+	err = AddScoped[*TestRepository](collection, func(s *Scope) any {
+		config, _ := GetService[TestConfig](s)
+		return &TestRepository{config: config}
+	})
+	assert.Nil(t, err)
+
+	collection.Lock()
+
+	scope, err := collection.CreateScope()
+	assert.Nil(t, err)
+
+	_, err = GetService[*TestRepository](scope)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{
+		"resolution_start *dependency_injection.TestRepository 0",
+		"provider_start *dependency_injection.TestRepository 0",
+		"resolution_start dependency_injection.TestConfig 1",
+		"provider_start dependency_injection.TestConfig 1",
+		"provider_end dependency_injection.TestConfig 1",
+		"resolution_end dependency_injection.TestConfig 1",
+		"provider_end *dependency_injection.TestRepository 0",
+		"resolution_end *dependency_injection.TestRepository 0",
+	}, kinds(recorder.Events()))
+
+	events := recorder.Events()
+	assert.Equal(t, "scoped", events[0].Lifetime)
+	assert.Equal(t, scope.ID(), events[0].ScopeID)
+	assert.Equal(t, "singleton", events[2].Lifetime)
+
+	recorder.Reset()
+
+	_, err = GetService[*TestRepository](scope)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{
+		"resolution_start *dependency_injection.TestRepository 0",
+		"cache_hit *dependency_injection.TestRepository 0",
+		"resolution_end *dependency_injection.TestRepository 0",
+	}, kinds(recorder.Events()))
+
+	recorder.Reset()
+
+	_, err = GetService[TestType](scope)
+	assert.NotNil(t, err)
+	events = recorder.Events()
+	assert.Equal(t, []string{
+		"resolution_start dependency_injection.TestType 0",
+		"resolution_end dependency_injection.TestType 0",
+	}, kinds(events))
+	assert.Equal(t, "unregistered", events[0].Lifetime)
+	assert.Equal(t, err, events[1].Err)
+}
+
+func TestRecorderWithFailedProvider(t *testing.T) {
+	collection := InitServiceCollection()
+
+	recorder := NewRecorder()
+	assert.Nil(t, collection.AddObserver(recorder))
+
+	err := AddTransient[TestType](collection, func(s *Scope) any {
+		return errNotReady
+	})
+	assert.Nil(t, err)
+
+	collection.Lock()
+
+	scope, err := collection.CreateScope()
+	assert.Nil(t, err)
+
+	_, err = GetService[TestType](scope)
+	assert.NotNil(t, err)
+
+	events := recorder.Events()
+	assert.Len(t, events, 4)
+	assert.Equal(t, EventProviderEnd, events[2].Kind)
+	assert.Equal(t, errNotReady, events[2].Err)
+	assert.Equal(t, EventResolutionEnd, events[3].Kind)
+	assert.Equal(t, err, events[3].Err)
+}
+
+// testSpan is a Span that keeps its attributes, events and parent.
+type testSpan struct {
+	name       string
+	parent     *testSpan
+	attributes map[string]any
+	events     []string
+	errors     []error
+	ended      bool
+}
+
+func (span *testSpan) SetAttribute(key string, value any) {
+	span.attributes[key] = value
+}
+
+func (span *testSpan) AddEvent(name string) {
+	span.events = append(span.events, name)
+}
+
+func (span *testSpan) RecordError(err error) {
+	span.errors = append(span.errors, err)
+}
+
+func (span *testSpan) End() {
+	span.ended = true
+}
+
+type testSpanKey struct{}
+
+// testTracer is a Tracer that keeps all started spans.
+type testTracer struct {
+	spans []*testSpan
+}
+
+func (tracer *testTracer) Start(ctx context.Context, spanName string) (context.Context, Span) {
+	parent, _ := ctx.Value(testSpanKey{}).(*testSpan)
+	span := &testSpan{
+		name:       spanName,
+		parent:     parent,
+		attributes: make(map[string]any),
+	}
+	tracer.spans = append(tracer.spans, span)
+
+	return context.WithValue(ctx, testSpanKey{}, span), span
+}
+
+func TestTracingObserver(t *testing.T) {
+	collection := InitServiceCollection()
+
+	tracer := &testTracer{}
+	assert.Nil(t, collection.AddObserver(NewTracingObserver(tracer)))
+
+	err := AddSingleton[TestConfig](collection, func(s *Scope) any {
+		return TestConfig{tenant: "default"}
+	})
+	assert.Nil(t, err)
+	err = AddScoped[*TestRepository](collection, func(s *Scope) any {
+		config, _ := GetService[TestConfig](s)
+		return &TestRepository{config: config}
+	})
+	assert.Nil(t, err)
+
+	collection.Lock()
+
+	scope, err := collection.CreateScope()
+	assert.Nil(t, err)
+
+	_, err = GetService[*TestRepository](scope)
+	assert.Nil(t, err)
+	_, err = GetService[TestConfig](scope)
+	assert.Nil(t, err)
+
+	assert.Len(t, tracer.spans, 3)
+
+	repositorySpan, configSpan, cachedSpan := tracer.spans[0], tracer.spans[1], tracer.spans[2]
+
+	assert.Equal(t, "di.resolve *dependency_injection.TestRepository", repositorySpan.name)
+	assert.Nil(t, repositorySpan.parent)
+	assert.Equal(t, map[string]any{
+		AttributeService:  "*dependency_injection.TestRepository",
+		AttributeLifetime: "scoped",
+		AttributeScopeID:  scope.ID(),
+		AttributeDepth:    0,
+	}, repositorySpan.attributes)
+	assert.Equal(t, []string{"provider_start", "provider_end"}, repositorySpan.events)
+
+	assert.Equal(t, "di.resolve dependency_injection.TestConfig", configSpan.name)
+	assert.Same(t, repositorySpan, configSpan.parent)
+	assert.Equal(t, 1, configSpan.attributes[AttributeDepth])
+
+	assert.Nil(t, cachedSpan.parent)
+	assert.Equal(t, true, cachedSpan.attributes[AttributeCacheHit])
+
+	for _, span := range tracer.spans {
+		assert.True(t, span.ended)
+		assert.Empty(t, span.errors)
+	}
+}
+
+func TestTracingObserverWithScopeContext(t *testing.T) {
+	collection := InitServiceCollection()
+
+	tracer := &testTracer{}
+	assert.Nil(t, collection.AddObserver(NewTracingObserver(tracer)))
+
+	err := AddSingleton[TestConfig](collection, func(s *Scope) any {
+		return TestConfig{tenant: "default"}
+	})
+	assert.Nil(t, err)
+	err = AddScoped[*TestRepository](collection, func(s *Scope) any {
+		config, _ := GetService[TestConfig](s)
+		return &TestRepository{config: config}
+	})
+	assert.Nil(t, err)
+
+	collection.Lock()
+
+	ctx, requestSpan := tracer.Start(context.Background(), "GET /users")
+	scope, err := collection.CreateScopeWithContext(ctx)
+	assert.Nil(t, err)
+
+	_, err = GetService[*TestRepository](scope)
+	assert.Nil(t, err)
+
+	assert.Len(t, tracer.spans, 3)
+
+	repositorySpan, configSpan := tracer.spans[1], tracer.spans[2]
+	assert.Same(t, requestSpan, repositorySpan.parent, "resolutions of the scope must be children of the request span")
+	assert.Same(t, repositorySpan, configSpan.parent)
+}
@@ -0,0 +1,303 @@
+package dependency_injection
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Options wraps options which are bound by AddOptions, it can be requested instead of the options.
+type Options[T any] struct {
+	value T
+}
+
+// Value returns the bound options.
+func (o Options[T]) Value() T {
+	return o.value
+}
+
+// OptionsValidator is implemented by options which validate themselves after they are bound.
+type OptionsValidator interface {
+	Validate() error
+}
+
+// durationType is the reflect type of time.Duration which is bound from duration strings like "5s".
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// AddOptions registers struct T and Options[T] as singletons which are bound from given section of the configuration
+// sources of the collection, see AddConfigSource. An empty section binds T from the root of the configuration.
+//
+// Fields are bound from keys which are their lowercase names or the names in their config tag, nested structs are
+// sections. Values of default tags are bound before values of sources. Fields are validated by their validate tag,
+// a comma separated list of required, min=n, max=n and oneof=a b rules, and T is validated by its Validate method
+// if it implements OptionsValidator. Binding and validation errors name the offending key.
+//
+// Options are singletons, so child collections share the options of their parents, which are bound from the sources
+// of the parents. Register the options again in a child collection to bind them from the sources of the child too.
+//
+//	type ServerOptions struct {
+//		Host    string        `default:"localhost"`
+//		Port    int           `default:"8080" validate:"min=1,max=65535"`
+//		Timeout time.Duration `config:"read_timeout" default:"5s"`
+//	}
+func AddOptions[T any](collection *ServiceCollection, section string, opts ...ServiceOption) error {
+	if err := collection.checkLock(); err != nil {
+		return err
+	}
+
+	reflectType := getReflectType[T]()
+	if reflectType.Kind() != reflect.Struct {
+		return fmt.Errorf("options %v must be a struct", reflectType.String())
+	}
+
+	section = strings.ToLower(section)
+
+	collection.add(reflectType, SINGLETON, func(s *Scope) any {
+		values, err := s.collection.loadConfig()
+		if err != nil {
+			return err
+		}
+
+		var options T
+		if err = bindOptions(values, section, reflect.ValueOf(&options).Elem()); err != nil {
+			return err
+		}
+
+		return options
+	}, opts...)
+
+	collection.add(getReflectType[Options[T]](), SINGLETON, func(s *Scope) any {
+		options, err := GetService[T](s)
+		if err != nil {
+			return err
+		}
+
+		return Options[T]{value: options}
+	})
+
+	return nil
+}
+
+// bindOptions binds the fields of a struct from the values of given section and validates it.
+func bindOptions(values map[string]any, section string, v reflect.Value) error {
+	t := v.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		key, bound := fieldConfigKey(section, field)
+		if !bound {
+			continue
+		}
+
+		if value, exists := field.Tag.Lookup("default"); exists {
+			if err := bindValue(v.Field(i), key, value); err != nil {
+				return err
+			}
+		}
+
+		if field.Type.Kind() == reflect.Struct {
+			if err := bindOptions(values, key, v.Field(i)); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if value, exists := values[key]; exists {
+			if err := bindValue(v.Field(i), key, value); err != nil {
+				return err
+			}
+		}
+
+		if rules, exists := field.Tag.Lookup("validate"); exists {
+			if err := validateField(v.Field(i), key, rules); err != nil {
+				return err
+			}
+		}
+	}
+
+	if validator, ok := v.Addr().Interface().(OptionsValidator); ok {
+		if err := validator.Validate(); err != nil {
+			if section == "" {
+				return fmt.Errorf("invalid options %v: %w", t.String(), err)
+			}
+			return fmt.Errorf("invalid configuration section %v: %w", section, err)
+		}
+	}
+
+	return nil
+}
+
+// fieldConfigKey returns the key of a field in given section, false if the field isn't bound.
+func fieldConfigKey(section string, field reflect.StructField) (string, bool) {
+	if !field.IsExported() {
+		return "", false
+	}
+
+	name := field.Name
+	if tag, exists := field.Tag.Lookup("config"); exists {
+		if tag == "-" {
+			return "", false
+		}
+		name = tag
+	}
+
+	return joinConfigKey(section, name), true
+}
+
+// bindValue sets a configuration value of given key to a field.
+func bindValue(v reflect.Value, key string, value any) error {
+	if items, ok := value.([]any); ok {
+		if v.Kind() != reflect.Slice {
+			return fmt.Errorf("configuration key %v must not be a list", key)
+		}
+
+		slice := reflect.MakeSlice(v.Type(), len(items), len(items))
+		for i, item := range items {
+			if err := bindValue(slice.Index(i), fmt.Sprintf("%v[%d]", key, i), item); err != nil {
+				return err
+			}
+		}
+		v.Set(slice)
+
+		return nil
+	}
+
+	text := configString(value)
+
+	var err error
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(text)
+	case reflect.Bool:
+		var b bool
+		if b, err = strconv.ParseBool(text); err == nil {
+			v.SetBool(b)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var i int64
+		if v.Type() == durationType {
+			var d time.Duration
+			d, err = time.ParseDuration(text)
+			i = int64(d)
+		} else {
+			i, err = strconv.ParseInt(text, 10, v.Type().Bits())
+		}
+		if err == nil {
+			v.SetInt(i)
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		var u uint64
+		if u, err = strconv.ParseUint(text, 10, v.Type().Bits()); err == nil {
+			v.SetUint(u)
+		}
+	case reflect.Float32, reflect.Float64:
+		var f float64
+		if f, err = strconv.ParseFloat(text, v.Type().Bits()); err == nil {
+			v.SetFloat(f)
+		}
+	case reflect.Slice:
+		// Lists of environment variables and flags are comma separated.
+		items := make([]any, 0)
+		if text != "" {
+			for _, item := range strings.Split(text, ",") {
+				items = append(items, strings.TrimSpace(item))
+			}
+		}
+		return bindValue(v, key, items)
+	default:
+		return fmt.Errorf("configuration key %v has unsupported type %v", key, v.Type().String())
+	}
+
+	if err != nil {
+		return fmt.Errorf("invalid value %q of configuration key %v: %w", text, key, err)
+	}
+
+	return nil
+}
+
+// configString returns the text of a scalar configuration value.
+func configString(value any) string {
+	switch value := value.(type) {
+	case string:
+		return value
+	case json.Number:
+		return value.String()
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	case nil:
+		return ""
+	}
+
+	return fmt.Sprint(value)
+}
+
+// validateField checks the value of a field against the rules of its validate tag.
+func validateField(v reflect.Value, key string, rules string) error {
+	for _, rule := range strings.Split(rules, ",") {
+		name, argument, _ := strings.Cut(strings.TrimSpace(rule), "=")
+
+		switch name {
+		case "":
+		case "required":
+			if v.IsZero() {
+				return fmt.Errorf("configuration key %v is required", key)
+			}
+		case "min", "max":
+			limit, err := strconv.ParseFloat(argument, 64)
+			if err != nil {
+				return fmt.Errorf("invalid %v rule %q of configuration key %v", name, argument, key)
+			}
+
+			size, isLength, supported := validatedSize(v)
+			if !supported {
+				return fmt.Errorf("%v rule is not supported for configuration key %v of type %v", name, key, v.Type().String())
+			}
+			if name == "min" && size < limit {
+				if isLength {
+					return fmt.Errorf("length of configuration key %v must be at least %v", key, argument)
+				}
+				return fmt.Errorf("configuration key %v must be at least %v", key, argument)
+			}
+			if name == "max" && size > limit {
+				if isLength {
+					return fmt.Errorf("length of configuration key %v must be at most %v", key, argument)
+				}
+				return fmt.Errorf("configuration key %v must be at most %v", key, argument)
+			}
+		case "oneof":
+			allowed := strings.Fields(argument)
+			value := fmt.Sprint(v.Interface())
+			valid := false
+			for _, a := range allowed {
+				valid = valid || a == value
+			}
+			if !valid {
+				return fmt.Errorf("configuration key %v must be one of %v", key, strings.Join(allowed, ", "))
+			}
+		default:
+			return fmt.Errorf("unknown validation rule %v of configuration key %v", name, key)
+		}
+	}
+
+	return nil
+}
+
+// validatedSize returns the number that min and max rules are checked against, the length for strings and lists.
+// It returns false as last result if the rules can't be checked for the kind of the value.
+func validatedSize(v reflect.Value) (float64, bool, bool) {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), false, true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), false, true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), false, true
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		return float64(v.Len()), true, true
+	}
+
+	return 0, false, false
+}
@@ -0,0 +1,217 @@
+package dependency_injection
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type TestDatabaseOptions struct {
+	Host     string `default:"localhost" validate:"required"`
+	Port     int    `default:"5432" validate:"min=1,max=65535"`
+	Replicas []string
+}
+
+type TestServerOptions struct {
+	Name     string        `validate:"required"`
+	Mode     string        `default:"release" validate:"oneof=debug release"`
+	Timeout  time.Duration `config:"read_timeout" default:"5s"`
+	Debug    bool
+	Ratio    float64
+	Database TestDatabaseOptions
+	internal string
+}
+
+func (o TestServerOptions) Validate() error {
+	if o.Debug && o.Mode != "debug" {
+		return fmt.Errorf("debug is enabled in %v mode", o.Mode)
+	}
+
+	return nil
+}
+
+// writeConfigFile writes a configuration file in a temporary directory of the test and returns its path.
+func writeConfigFile(t *testing.T, name string, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	assert.Nil(t, os.WriteFile(path, []byte(content), 0o600))
+
+	return path
+}
+
+// resolveServerOptions binds TestServerOptions from server section of given sources.
+func resolveServerOptions(sources ...ConfigSource) (TestServerOptions, error) {
+	collection := InitServiceCollection()
+	_ = collection.AddConfigSource(sources...)
+	_ = AddOptions[TestServerOptions](collection, "server")
+	collection.Lock()
+
+	scope, _ := collection.CreateScope()
+	defer scope.Close()
+
+	return GetService[TestServerOptions](scope)
+}
+
+func TestAddOptionsDefaults(t *testing.T) {
+	options, err := resolveServerOptions(MapSource(map[string]any{
+		"server": map[string]any{"name": "api"},
+	}))
+
+	assert.Nil(t, err)
+	assert.Equal(t, TestServerOptions{
+		Name:    "api",
+		Mode:    "release",
+		Timeout: 5 * time.Second,
+		Database: TestDatabaseOptions{
+			Host: "localhost",
+			Port: 5432,
+		},
+	}, options)
+}
+
+func TestAddOptionsLayeredSources(t *testing.T) {
+	jsonFile := writeConfigFile(t, "config.json", `{
+		"server": {
+			"name": "api",
+			"mode": "debug",
+			"read_timeout": "10s",
+			"ratio": 0.5,
+			"database": {"host": "db", "port": 5433, "replicas": ["r1", "r2"]}
+		}
+	}`)
+	overrideFile := writeConfigFile(t, "override.json", `{"server": {"database": {"port": 6000}}}`)
+	t.Setenv("TEST_SERVER__DEBUG", "true")
+	t.Setenv("TEST_SERVER__DATABASE__REPLICAS", "r3, r4")
+	t.Setenv("OTHER_SERVER__NAME", "other")
+
+	flags := flag.NewFlagSet("test", flag.ContinueOnError)
+	flags.String("server.name", "", "")
+	flags.Int("server.database.port", 0, "")
+	assert.Nil(t, flags.Parse([]string{"-server.name", "cli"}))
+
+	options, err := resolveServerOptions(JSONFileSource(jsonFile), JSONFileSource(overrideFile), EnvSource("TEST"), FlagSource(flags))
+
+	assert.Nil(t, err)
+	assert.Equal(t, TestServerOptions{
+		Name:    "cli",
+		Mode:    "debug",
+		Timeout: 10 * time.Second,
+		Debug:   true,
+		Ratio:   0.5,
+		Database: TestDatabaseOptions{
+			Host:     "db",
+			Port:     6000,
+			Replicas: []string{"r3", "r4"},
+		},
+	}, options, "later sources must override former ones and unset flags must not override them")
+}
+
+func TestAddOptionsErrorsNameTheKey(t *testing.T) {
+	tests := map[string]struct {
+		values map[string]any
+		err    string
+	}{
+		"invalid value": {
+			values: map[string]any{"server": map[string]any{"name": "api", "database": map[string]any{"port": "db"}}},
+			err:    `invalid value "db" of configuration key server.database.port`,
+		},
+		"required": {
+			values: map[string]any{},
+			err:    "configuration key server.name is required",
+		},
+		"max": {
+			values: map[string]any{"server": map[string]any{"name": "api", "database": map[string]any{"port": 70000}}},
+			err:    "configuration key server.database.port must be at most 65535",
+		},
+		"oneof": {
+			values: map[string]any{"server": map[string]any{"name": "api", "mode": "test"}},
+			err:    "configuration key server.mode must be one of debug, release",
+		},
+		"list": {
+			values: map[string]any{"server": map[string]any{"name": []any{"api"}}},
+			err:    "configuration key server.name must not be a list",
+		},
+		"validate method": {
+			values: map[string]any{"server": map[string]any{"name": "api", "debug": true}},
+			err:    "invalid configuration section server: debug is enabled in release mode",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := resolveServerOptions(MapSource(test.values))
+
+			assert.NotNil(t, err)
+			assert.Contains(t, err.Error(), test.err)
+		})
+	}
+}
+
+func TestAddOptionsSourceError(t *testing.T) {
+	_, err := resolveServerOptions(JSONFileSource(writeConfigFile(t, "config.json", "{")))
+
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "can't parse configuration file")
+}
+
+func TestAddOptionsWrapper(t *testing.T) {
+	collection := InitServiceCollection()
+	_ = collection.AddConfigSource(MapSource(map[string]any{"host": "db"}))
+	assert.Nil(t, AddOptions[TestDatabaseOptions](collection, ""))
+	collection.Lock()
+
+	scope, _ := collection.CreateScope()
+	defer scope.Close()
+
+	options, err := GetService[Options[TestDatabaseOptions]](scope)
+	assert.Nil(t, err)
+	assert.Equal(t, TestDatabaseOptions{Host: "db", Port: 5432}, options.Value())
+}
+
+func TestAddOptionsOfNonStruct(t *testing.T) {
+	collection := InitServiceCollection()
+
+	err := AddOptions[*TestDatabaseOptions](collection, "database")
+
+	assert.Equal(t, fmt.Errorf("options *dependency_injection.TestDatabaseOptions must be a struct"), err)
+}
+
+func TestAddOptionsInheritsConfigSources(t *testing.T) {
+	parent := InitServiceCollection()
+	_ = parent.AddConfigSource(MapSource(map[string]any{"database": map[string]any{"host": "parent", "port": 1}}))
+	parent.Lock()
+
+	child := parent.CreateChild()
+	_ = child.AddConfigSource(MapSource(map[string]any{"database": map[string]any{"host": "child"}}))
+	_ = AddOptions[TestDatabaseOptions](child, "database")
+	child.Lock()
+
+	scope, _ := child.CreateScope()
+	defer scope.Close()
+
+	options, err := GetService[TestDatabaseOptions](scope)
+	assert.Nil(t, err)
+	assert.Equal(t, TestDatabaseOptions{Host: "child", Port: 1}, options)
+}
+
+func TestInheritedOptionsUseConfigSourcesOfParent(t *testing.T) {
+	parent := InitServiceCollection()
+	_ = parent.AddConfigSource(MapSource(map[string]any{"database": map[string]any{"host": "parent", "port": 1}}))
+	_ = AddOptions[TestDatabaseOptions](parent, "database")
+	parent.Lock()
+
+	child := parent.CreateChild()
+	_ = child.AddConfigSource(MapSource(map[string]any{"database": map[string]any{"host": "child"}}))
+	child.Lock()
+
+	scope, _ := child.CreateScope()
+	defer scope.Close()
+
+	options, err := GetService[TestDatabaseOptions](scope)
+	assert.Nil(t, err)
+	assert.Equal(t, TestDatabaseOptions{Host: "parent", Port: 1}, options, "inherited options should be shared")
+}
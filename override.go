@@ -0,0 +1,102 @@
+package dependency_injection
+
+import (
+	"reflect"
+	"sync/atomic"
+)
+
+// Override overrides service T in the scope and its child scopes with given provider, regardless of the lifetime
+// of its registration. Overridden services behave like scoped services, and providers that request T through the
+// scope retrieve the override too. Singletons are shared by all scopes, so their providers don't see overrides.
+func Override[T any](s *Scope, provider func(s *Scope) any) error {
+	if err := s.checkClosed(); err != nil {
+		return err
+	}
+
+	reflectType := getReflectType[T]()
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if !s.current() {
+		return errScopeClosed()
+	}
+
+	if s.overrides == nil {
+		s.overrides = make(map[reflect.Type]*ServiceType)
+	}
+	atomic.StoreUint32(&s.overridden, 1)
+	s.overrides[reflectType] = &ServiceType{
+		reflectType: reflectType,
+		id:          assignServiceID(reflectType),
+		lifetime:    SCOPED,
+		provider:    provider,
+		collection:  s.collection,
+	}
+	// The instance which is initialized before the override must not be retrieved anymore.
+	s.setInstance(s.overrides[reflectType].id, nil)
+
+	return nil
+}
+
+// OverrideValue overrides service T in the scope and its child scopes with given value, like Override.
+func OverrideValue[T any](s *Scope, value T) error {
+	return Override[T](s, func(s *Scope) any {
+		return value
+	})
+}
+
+// lookupOverride finds the override of a service in the scope or the scopes that it's created from.
+func (s *Scope) lookupOverride(t reflect.Type) (*ServiceType, bool) {
+	for current := s.scopeState; current != nil; current = current.parent {
+		if atomic.LoadUint32(&current.overridden) == 0 {
+			continue
+		}
+
+		current.mutex.RLock()
+		serviceType, exists := current.overrides[t]
+		current.mutex.RUnlock()
+
+		if exists {
+			return serviceType, true
+		}
+	}
+
+	return nil, false
+}
+
+// hasOverrides reports whether the scope or the scopes that it's created from have overrides.
+func (s *Scope) hasOverrides() bool {
+	for current := s.scopeState; current != nil; current = current.parent {
+		if atomic.LoadUint32(&current.overridden) == 1 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// CreateChild creates a new scope of the same collection and context which inherits the overrides of the scope.
+// The child has its own scoped services and must be closed independently of the scope.
+func (s *Scope) CreateChild() (*Scope, error) {
+	if err := s.checkClosed(); err != nil {
+		return nil, err
+	}
+
+	s.mutex.Lock()
+	if !s.current() {
+		s.mutex.Unlock()
+		return nil, errScopeClosed()
+	}
+	s.retained = true
+	s.mutex.Unlock()
+
+	atomic.AddInt64(&s.collection.liveScopes, 1)
+
+	child := s.collection.newScope()
+	child.ctx = s.Context()
+	child.parent = s.scopeState
+	s.collection.trackScope(child)
+
+	return child, nil
+}
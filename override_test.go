@@ -0,0 +1,144 @@
+package dependency_injection
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+type TestClock interface {
+	Now() string
+}
+
+type SystemClock struct{}
+
+func (clock *SystemClock) Now() string {
+	return "now"
+}
+
+type RecordedClock struct{}
+
+func (clock *RecordedClock) Now() string {
+	return "recorded"
+}
+
+type TestJob struct {
+	clock TestClock
+}
+
+func initOverrideCollection(t *testing.T, clockLifetime int) *ServiceCollection {
+	collection := InitServiceCollection()
+
+	collection.add(getReflectType[TestClock](), clockLifetime, func(s *Scope) any {
+		return &SystemClock{}
+	})
+	err := AddScoped[*TestJob](collection, func(s *Scope) any {
+		clock, _ := GetService[TestClock](s)
+		return &TestJob{clock: clock}
+	})
+	assert.Nil(t, err)
+
+	collection.Lock()
+
+	return collection
+}
+
+func TestOverride(t *testing.T) {
+	for _, lifetime := range []int{SINGLETON, SCOPED, TRANSIENT} {
+		t.Run(lifetimeName(lifetime), func(t *testing.T) {
+			collection := initOverrideCollection(t, lifetime)
+
+			scope, err := collection.CreateScope()
+			assert.Nil(t, err)
+			defer scope.Close()
+
+			clock, _ := GetService[TestClock](scope)
+			assert.Equal(t, "now", clock.Now())
+
+			assert.Nil(t, OverrideValue[TestClock](scope, &RecordedClock{}))
+
+			clock, _ = GetService[TestClock](scope)
+			assert.Equal(t, "recorded", clock.Now())
+
+			job, err := GetService[*TestJob](scope)
+			assert.Nil(t, err)
+			assert.Equal(t, "recorded", job.clock.Now(), "providers should retrieve the override")
+
+			otherScope, err := collection.CreateScope()
+			assert.Nil(t, err)
+			defer otherScope.Close()
+
+			job, err = GetService[*TestJob](otherScope)
+			assert.Nil(t, err)
+			assert.Equal(t, "now", job.clock.Now(), "other scopes should not be affected")
+		})
+	}
+}
+
+func TestOverrideInChildScope(t *testing.T) {
+	collection := initOverrideCollection(t, SCOPED)
+
+	scope, err := collection.CreateScope()
+	assert.Nil(t, err)
+	defer scope.Close()
+
+	calls := 0
+	err = Override[TestClock](scope, func(s *Scope) any {
+		calls++
+		return &RecordedClock{}
+	})
+	assert.Nil(t, err)
+
+	child, err := scope.CreateChild()
+	assert.Nil(t, err)
+	assert.NotEqual(t, scope.ID(), child.ID())
+
+	job, err := GetService[*TestJob](child)
+	assert.Nil(t, err)
+	assert.Equal(t, "recorded", job.clock.Now())
+
+	parentJob, err := GetService[*TestJob](scope)
+	assert.Nil(t, err)
+	assert.NotSame(t, job, parentJob, "child scopes should have their own scoped services")
+	assert.Equal(t, 2, calls)
+
+	assert.Nil(t, child.Close())
+	assert.Equal(t, int64(1), collection.Metrics().LiveScopes)
+}
+
+func TestOverrideDoesNotLeakToSingletons(t *testing.T) {
+	collection := InitServiceCollection()
+
+	err := AddScoped[TestClock](collection, func(s *Scope) any {
+		return &SystemClock{}
+	})
+	assert.Nil(t, err)
+	err = AddSingleton[*TestJob](collection, func(s *Scope) any {
+		clock, _ := GetService[TestClock](s)
+		return &TestJob{clock: clock}
+	})
+	assert.Nil(t, err)
+
+	collection.Lock()
+
+	scope, err := collection.CreateScope()
+	assert.Nil(t, err)
+	defer scope.Close()
+
+	assert.Nil(t, OverrideValue[TestClock](scope, &RecordedClock{}))
+
+	job, err := GetService[*TestJob](scope)
+	assert.Nil(t, err)
+	assert.Equal(t, "now", job.clock.Now())
+}
+
+func TestOverrideClosedScope(t *testing.T) {
+	collection := initOverrideCollection(t, SCOPED)
+
+	scope, err := collection.CreateScope()
+	assert.Nil(t, err)
+	assert.Nil(t, scope.Close())
+
+	assert.NotNil(t, OverrideValue[TestClock](scope, &RecordedClock{}))
+	_, err = scope.CreateChild()
+	assert.NotNil(t, err)
+}
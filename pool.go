@@ -0,0 +1,121 @@
+package dependency_injection
+
+import (
+	"fmt"
+	"sync"
+)
+
+// defaultPoolSize is the maximum number of idle instances that object pool of a pooled service keeps
+// if WithPoolSize is not used while registering the service.
+const defaultPoolSize = 16
+
+// PoolStats is a snapshot of the statistics of the object pool of a pooled service.
+type PoolStats struct {
+	// Number of requests that were served by an idle instance of the pool.
+	Hits uint64
+	// Number of requests that initialized a new instance because the pool was empty.
+	Misses uint64
+	// Number of returned instances that were dropped because the pool was full.
+	Evictions uint64
+	// Number of idle instances which are currently kept in the pool.
+	Idle int
+	// Maximum number of idle instances that the pool keeps.
+	Size int
+}
+
+// objectPool is a bounded pool of idle instances of a pooled service.
+type objectPool struct {
+	// Maximum number of idle instances that the pool keeps.
+	size int
+	// Idle instances which are ready to be borrowed.
+	idle []any
+	// Statistics of the pool.
+	hits      uint64
+	misses    uint64
+	evictions uint64
+	// A mutex to handle data race while borrowing or returning instances.
+	mutex sync.Mutex
+}
+
+// newObjectPool initialize an object pool with given size
+func newObjectPool(size int) *objectPool {
+	return &objectPool{
+		size:  size,
+		idle:  make([]any, 0, size),
+		mutex: sync.Mutex{},
+	}
+}
+
+// get borrows an idle instance from the pool, the second returned value will be false if the pool is empty.
+func (pool *objectPool) get() (any, bool) {
+	pool.mutex.Lock()
+	defer pool.mutex.Unlock()
+
+	if len(pool.idle) == 0 {
+		pool.misses++
+		return nil, false
+	}
+
+	last := len(pool.idle) - 1
+	value := pool.idle[last]
+	pool.idle[last] = nil
+	pool.idle = pool.idle[:last]
+	pool.hits++
+
+	return value, true
+}
+
+// put returns an instance to the pool, the returned value will be false if the instance was evicted.
+func (pool *objectPool) put(value any) bool {
+	pool.mutex.Lock()
+	defer pool.mutex.Unlock()
+
+	if len(pool.idle) >= pool.size {
+		pool.evictions++
+		return false
+	}
+
+	pool.idle = append(pool.idle, value)
+
+	return true
+}
+
+// stats returns a snapshot of the statistics of the pool.
+func (pool *objectPool) stats() PoolStats {
+	pool.mutex.Lock()
+	defer pool.mutex.Unlock()
+
+	return PoolStats{
+		Hits:      pool.hits,
+		Misses:    pool.misses,
+		Evictions: pool.evictions,
+		Idle:      len(pool.idle),
+		Size:      pool.size,
+	}
+}
+
+// release resets an instance of a pooled service and returns it to the object pool of the service.
+func (serviceType *ServiceType) release(value any) {
+	if serviceType.reset != nil {
+		serviceType.reset(value)
+	}
+
+	serviceType.pool.put(value)
+}
+
+// GetPoolStats returns statistics of the object pool of a pooled service.
+func GetPoolStats[T any](collection *ServiceCollection) (PoolStats, error) {
+	reflectType := getReflectType[T]()
+
+	serviceType, exists := collection.lookup(reflectType)
+
+	if !exists {
+		return PoolStats{}, fmt.Errorf("service %v is not registered in service collection", reflectType.String())
+	}
+
+	if serviceType.lifetime != POOLED {
+		return PoolStats{}, fmt.Errorf("service %v is not registered as pooled", reflectType.String())
+	}
+
+	return serviceType.pool.stats(), nil
+}
@@ -0,0 +1,142 @@
+package dependency_injection
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+type TestBuffer struct {
+	data []byte
+}
+
+func TestGetPooledService(t *testing.T) {
+	collection := InitServiceCollection()
+
+	counter := 0
+	resetCounter := 0
+
+	err := AddPooled[*TestBuffer](collection, func(s *Scope) any {
+		counter++
+		return &TestBuffer{}
+	}, func(buffer *TestBuffer) {
+		resetCounter++
+		buffer.data = buffer.data[:0]
+	})
+	assert.Nil(t, err)
+
+	collection.Lock()
+
+	var firstScopeBuffer *TestBuffer
+
+	{
+		// Create first scope
+		scope1, err := collection.CreateScope()
+		assert.Nil(t, err)
+
+		// Retrieve services for first time in scope-1
+		firstScopeBuffer, err = GetService[*TestBuffer](scope1)
+		assert.Nil(t, err)
+		firstScopeBuffer.data = append(firstScopeBuffer.data, 'a')
+
+		// Retrieve services again in scope-1
+		buffer, err := GetService[*TestBuffer](scope1)
+		assert.Nil(t, err)
+		assert.Same(t, firstScopeBuffer, buffer)
+		assert.Equal(t, 1, counter)
+
+		assert.Nil(t, scope1.Close())
+		assert.Equal(t, 1, resetCounter)
+		assert.Empty(t, firstScopeBuffer.data)
+	}
+	{
+		// Create second scope
+		scope2, err := collection.CreateScope()
+		assert.Nil(t, err)
+
+		// Returned instance of scope-1 should be reused in scope-2
+		buffer, err := GetService[*TestBuffer](scope2)
+		assert.Nil(t, err)
+		assert.Same(t, firstScopeBuffer, buffer)
+		assert.Equal(t, 1, counter)
+
+		assert.Nil(t, scope2.Close())
+	}
+
+	stats, err := GetPoolStats[*TestBuffer](collection)
+	assert.Nil(t, err)
+	assert.Equal(t, PoolStats{Hits: 1, Misses: 1, Evictions: 0, Idle: 1, Size: defaultPoolSize}, stats)
+}
+
+func TestPooledServiceEviction(t *testing.T) {
+	collection := InitServiceCollection()
+
+	err := AddPooled[*TestBuffer](collection, func(s *Scope) any {
+		return &TestBuffer{}
+	}, nil, WithPoolSize(1))
+	assert.Nil(t, err)
+
+	collection.Lock()
+
+	scope1, err := collection.CreateScope()
+	assert.Nil(t, err)
+	scope2, err := collection.CreateScope()
+	assert.Nil(t, err)
+
+	buffer1, err := GetService[*TestBuffer](scope1)
+	assert.Nil(t, err)
+	buffer2, err := GetService[*TestBuffer](scope2)
+	assert.Nil(t, err)
+	assert.NotSame(t, buffer1, buffer2)
+
+	assert.Nil(t, scope1.Close())
+	assert.Nil(t, scope2.Close())
+
+	stats, err := GetPoolStats[*TestBuffer](collection)
+	assert.Nil(t, err)
+	assert.Equal(t, PoolStats{Hits: 0, Misses: 2, Evictions: 1, Idle: 1, Size: 1}, stats)
+}
+
+func TestAddPooledServiceWithInvalidPoolSize(t *testing.T) {
+	collection := InitServiceCollection()
+
+	err := AddPooled[*TestBuffer](collection, func(s *Scope) any {
+		return &TestBuffer{}
+	}, nil, WithPoolSize(0))
+	assert.NotNil(t, err)
+	assert.Equal(t, fmt.Errorf("pool size of service *dependency_injection.TestBuffer must be greater than zero"), err)
+}
+
+func TestGetPoolStatsOfNotPooledService(t *testing.T) {
+	collection := InitServiceCollection()
+
+	err := AddScoped[*TestBuffer](collection, func(s *Scope) any {
+		return &TestBuffer{}
+	})
+	assert.Nil(t, err)
+
+	_, err = GetPoolStats[*TestBuffer](collection)
+	assert.NotNil(t, err)
+	assert.Equal(t, fmt.Errorf("service *dependency_injection.TestBuffer is not registered as pooled"), err)
+}
+
+func TestGetServiceFromClosedScope(t *testing.T) {
+	collection := InitServiceCollection()
+
+	err := AddScoped[TestType](collection, func(s *Scope) any {
+		return TestType{}
+	})
+	assert.Nil(t, err)
+
+	collection.Lock()
+
+	scope, err := collection.CreateScope()
+	assert.Nil(t, err)
+
+	assert.Nil(t, scope.Close())
+	assert.Equal(t, fmt.Errorf("scope is already closed"), scope.Close())
+
+	_, err = GetService[TestType](scope)
+	assert.NotNil(t, err)
+	assert.Equal(t, fmt.Errorf("scope is closed, you can't request services from it"), err)
+}
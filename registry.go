@@ -0,0 +1,68 @@
+package dependency_injection
+
+import (
+	"reflect"
+)
+
+// registry is an immutable table of the registrations of a locked collection by their type.
+// It's published by Lock, so services can be looked up without locks while scopes resolve them concurrently.
+// A resolution hashes the type of the service once to find its registration, the ID of the registration is used
+// to find the instances of the service in scopes.
+type registry map[reflect.Type]*ServiceType
+
+// singletonInstance is an initialized instance of a singleton service.
+type singletonInstance struct {
+	value any
+}
+
+// publishRegistry publishes a copy of the registrations as the registry of the collection.
+// Changes of registrations after locking, like replace, must publish the registry again.
+func (collection *ServiceCollection) publishRegistry() {
+	frozen := make(registry, len(collection.registeredServicePool))
+	for t, serviceType := range collection.registeredServicePool {
+		frozen[t] = serviceType
+	}
+
+	collection.registry.Store(frozen)
+}
+
+// registrations returns the registrations of the collection, it's the published registry if the collection is locked.
+func (collection *ServiceCollection) registrations() map[reflect.Type]*ServiceType {
+	if frozen, published := collection.registry.Load().(registry); published {
+		return frozen
+	}
+
+	return collection.registeredServicePool
+}
+
+// lookup finds the configuration of a service in the collection or its ancestors
+func (collection *ServiceCollection) lookup(t reflect.Type) (*ServiceType, bool) {
+	for current := collection; current != nil; current = current.parent {
+		if serviceType, exists := current.registrations()[t]; exists {
+			return serviceType, true
+		}
+	}
+
+	return nil, false
+}
+
+// loadInstance returns the initialized instance of a singleton service without taking any lock.
+func (serviceType *ServiceType) loadInstance() (any, bool) {
+	instance, _ := serviceType.instance.Load().(*singletonInstance)
+	if instance == nil {
+		return nil, false
+	}
+
+	return instance.value, true
+}
+
+// storeInstance publishes the initialized instance of a singleton service, nil removes the instance.
+// It must be called with the mutex of the collection that owns the service, along with its singleton pool.
+func (serviceType *ServiceType) storeInstance(value any) {
+	if value == nil {
+		serviceType.instance.Store((*singletonInstance)(nil))
+		return
+	}
+
+	serviceType.instance.Store(&singletonInstance{value: value})
+}
@@ -0,0 +1,221 @@
+package dependency_injection
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRegistryIsPublishedOnLock(t *testing.T) {
+	collection := InitServiceCollection()
+
+	err := AddSingleton[TestConfig](collection, func(s *Scope) any {
+		return TestConfig{tenant: "first"}
+	})
+	assert.Nil(t, err)
+
+	_, published := collection.registry.Load().(registry)
+	assert.False(t, published)
+
+	collection.Lock()
+
+	frozen, published := collection.registry.Load().(registry)
+	assert.True(t, published)
+	assert.Len(t, frozen, 1)
+
+	scope, err := collection.CreateScope()
+	assert.Nil(t, err)
+	defer scope.Close()
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			config, err := GetService[TestConfig](scope)
+			assert.Nil(t, err)
+			assert.Equal(t, "first", config.tenant)
+		}()
+	}
+	wg.Wait()
+
+	assert.Nil(t, Invalidate[TestConfig](collection))
+	_, built := frozen[getReflectType[TestConfig]()].loadInstance()
+	assert.False(t, built, "invalidated singleton should be removed from the registry")
+}
+
+// resolveConcurrently resolves service T from a new scope by given number of goroutines at the same time.
+func resolveConcurrently[T any](collection *ServiceCollection, goroutines int) ([]T, []error) {
+	values := make([]T, goroutines)
+	errs := make([]error, goroutines)
+
+	start := make(chan struct{})
+	wg := sync.WaitGroup{}
+	wg.Add(goroutines)
+	for g := 0; g < goroutines; g++ {
+		go func(g int) {
+			defer wg.Done()
+			scope, _ := collection.CreateScope()
+			defer scope.Close()
+
+			<-start
+			values[g], errs[g] = GetService[T](scope)
+		}(g)
+	}
+	close(start)
+	wg.Wait()
+
+	return values, errs
+}
+
+func TestSingletonIsBuiltOnceConcurrently(t *testing.T) {
+	collection := InitServiceCollection()
+
+	calls := int64(0)
+	err := AddSingleton[*TestRepository](collection, func(s *Scope) any {
+		atomic.AddInt64(&calls, 1)
+		time.Sleep(10 * time.Millisecond)
+		return &TestRepository{}
+	})
+	assert.Nil(t, err)
+
+	collection.Lock()
+
+	values, errs := resolveConcurrently[*TestRepository](collection, 16)
+
+	assert.Equal(t, int64(1), atomic.LoadInt64(&calls))
+	for g := range values {
+		assert.Nil(t, errs[g])
+		assert.Same(t, values[0], values[g])
+	}
+}
+
+func TestFailedSingletonIsBuiltAgainConcurrently(t *testing.T) {
+	collection := InitServiceCollection()
+
+	calls := int64(0)
+	err := AddSingleton[*TestRepository](collection, func(s *Scope) any {
+		if atomic.AddInt64(&calls, 1) == 1 {
+			time.Sleep(10 * time.Millisecond)
+			return fmt.Errorf("not ready")
+		}
+		return &TestRepository{}
+	})
+	assert.Nil(t, err)
+
+	collection.Lock()
+
+	values, errs := resolveConcurrently[*TestRepository](collection, 16)
+
+	failures := 0
+	var instance *TestRepository
+	for g := range values {
+		if errs[g] != nil {
+			failures++
+			continue
+		}
+		if instance == nil {
+			instance = values[g]
+		}
+		assert.Same(t, instance, values[g])
+	}
+
+	assert.Equal(t, 1, failures, "only the failed build must return its error")
+	assert.Equal(t, int64(2), atomic.LoadInt64(&calls), "the failure must not be cached")
+}
+
+func TestSingletonWithCircularDependency(t *testing.T) {
+	collection := InitServiceCollection()
+
+	err := AddSingleton[TestConfig](collection, func(s *Scope) any {
+		_, err := GetService[*TestRepository](s)
+		if err != nil {
+			return err
+		}
+		return TestConfig{}
+	})
+	assert.Nil(t, err)
+	err = AddSingleton[*TestRepository](collection, func(s *Scope) any {
+		_, err := GetService[TestConfig](s)
+		if err != nil {
+			return err
+		}
+		return &TestRepository{}
+	})
+	assert.Nil(t, err)
+
+	collection.Lock()
+
+	scope, _ := collection.CreateScope()
+	defer scope.Close()
+
+	_, err = GetService[TestConfig](scope)
+
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "circular dependency of singleton service dependency_injection.TestConfig")
+}
+
+// runGoroutines runs fn b.N times in total on given number of goroutines.
+func runGoroutines(b *testing.B, goroutines int, fn func()) {
+	wg := sync.WaitGroup{}
+	wg.Add(goroutines)
+
+	b.ResetTimer()
+	for g := 0; g < goroutines; g++ {
+		iterations := b.N / goroutines
+		if g < b.N%goroutines {
+			iterations++
+		}
+
+		go func() {
+			defer wg.Done()
+			for i := 0; i < iterations; i++ {
+				fn()
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// BenchmarkSingletonHotPath compares retrieving a built singleton from the singleton pool under the read lock of the
+// collection, which was used before publishing the registry, with the lock-free registry and the whole GetService.
+func BenchmarkSingletonHotPath(b *testing.B) {
+	collection := InitServiceCollection()
+	_ = AddSingleton[TestConfig](collection, func(s *Scope) any {
+		return TestConfig{tenant: "benchmark"}
+	})
+	collection.Lock()
+
+	scope, _ := collection.CreateScope()
+	defer scope.Close()
+	_, _ = GetService[TestConfig](scope)
+
+	reflectType := getReflectType[TestConfig]()
+	serviceType, _ := collection.lookup(reflectType)
+
+	for _, goroutines := range []int{1, 8, 64} {
+		b.Run(fmt.Sprintf("rwmutex/goroutines=%v", goroutines), func(b *testing.B) {
+			runGoroutines(b, goroutines, func() {
+				collection.mutex.RLock()
+				_ = collection.singletonServicePool[reflectType].(TestConfig)
+				collection.mutex.RUnlock()
+			})
+		})
+
+		b.Run(fmt.Sprintf("registry/goroutines=%v", goroutines), func(b *testing.B) {
+			runGoroutines(b, goroutines, func() {
+				value, _ := serviceType.loadInstance()
+				_ = value.(TestConfig)
+			})
+		})
+
+		b.Run(fmt.Sprintf("GetService/goroutines=%v", goroutines), func(b *testing.B) {
+			runGoroutines(b, goroutines, func() {
+				_, _ = GetService[TestConfig](scope)
+			})
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package dependency_injection
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"time"
+)
+
+// RetryPolicy configures how failed providers of a service will be retried.
+// Providers report a failure by returning an error instead of the instance of the service.
+type RetryPolicy struct {
+	// Maximum number of attempts to initialize the service, including the first attempt.
+	MaxAttempts int
+	// Delay before the second attempt, the delay will be multiplied by Multiplier for every next attempt.
+	InitialBackoff time.Duration
+	// Maximum delay between two attempts, zero means the delay is not limited.
+	MaxBackoff time.Duration
+	// Multiplier of the delay between attempts, values less than 1 will be treated as 2.
+	Multiplier float64
+	// Fraction of each delay between 0 and 1 which will be randomized to avoid attempts of different requests at the same time.
+	Jitter float64
+	// Decides whether initializing the service should be retried after given error, nil means all errors are retryable.
+	Retryable func(err error) bool
+}
+
+// WithRetry sets the retry policy of a service for the times that its provider fails.
+func WithRetry(policy RetryPolicy) ServiceOption {
+	return func(serviceType *ServiceType) {
+		serviceType.retryPolicy = &policy
+	}
+}
+
+// sleep pauses the current goroutine between attempts, tests can replace it to avoid waiting.
+var sleep = time.Sleep
+
+// backoff calculates the delay before given attempt, attempts start from 1.
+func (policy *RetryPolicy) backoff(attempt int) time.Duration {
+	multiplier := policy.Multiplier
+	if multiplier < 1 {
+		multiplier = 2
+	}
+
+	delay := float64(policy.InitialBackoff)
+	for i := 2; i < attempt; i++ {
+		delay *= multiplier
+		if policy.MaxBackoff > 0 && delay > float64(policy.MaxBackoff) {
+			break
+		}
+	}
+
+	if policy.MaxBackoff > 0 && delay > float64(policy.MaxBackoff) {
+		delay = float64(policy.MaxBackoff)
+	}
+
+	if policy.Jitter > 0 {
+		jitter := policy.Jitter
+		if jitter > 1 {
+			jitter = 1
+		}
+		delay = delay*(1-jitter) + delay*jitter*rand.Float64()
+	}
+
+	return time.Duration(delay)
+}
+
+// isRetryable checks if initializing the service should be retried after given error.
+func (policy *RetryPolicy) isRetryable(err error) bool {
+	return policy.Retryable == nil || policy.Retryable(err)
+}
+
+// callProvider calls provider of the service once, an error is returned if the provider returns an error instead of the service.
+func callProvider[T any](s *Scope, serviceType *ServiceType, o *observation) (any, error) {
+	o.startProvider()
+
+	value := serviceType.provider(s.resolvingScope(serviceType, o))
+
+	if err, failed := value.(error); failed {
+		if _, isService := value.(T); !isService {
+			o.endProvider(err)
+			return nil, err
+		}
+	}
+
+	o.endProvider(nil)
+
+	return value, nil
+}
+
+// buildService initializes a new instance of the service and retries failed attempts based on retry policy of the service.
+func buildService[T any](s *Scope, reflectType reflect.Type, serviceType *ServiceType, o *observation) (any, error) {
+	value, err := callProvider[T](s, serviceType, o)
+	if err == nil {
+		return value, nil
+	}
+
+	policy := serviceType.retryPolicy
+	if policy == nil || policy.MaxAttempts <= 1 {
+		return nil, fmt.Errorf("failed to initialize service %v: %w", reflectType.String(), err)
+	}
+
+	attempt := 1
+	for ; attempt < policy.MaxAttempts && policy.isRetryable(err); attempt++ {
+		delay := policy.backoff(attempt + 1)
+		if logger := s.collection.logger; logger.Enabled() {
+			logger.Debug("Retrying to initialize service",
+				Field{Key: FieldService, Value: reflectType.String()},
+				Field{Key: FieldLifetime, Value: lifetimeName(serviceType.lifetime)},
+				Field{Key: FieldScopeID, Value: s.id},
+				Field{Key: "attempt", Value: attempt + 1},
+				Field{Key: "delay", Value: delay},
+				Field{Key: "error", Value: err},
+			)
+		}
+		sleep(delay)
+
+		value, err = callProvider[T](s, serviceType, o)
+		if err == nil {
+			return value, nil
+		}
+	}
+
+	return nil, fmt.Errorf("failed to initialize service %v after %v attempts: %w", reflectType.String(), attempt, err)
+}
@@ -0,0 +1,141 @@
+package dependency_injection
+
+import (
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+var errNotReady = errors.New("database is not ready")
+
+// recordSleeps replaces sleep function of the package with one that records delays until the end of the test.
+func recordSleeps(t *testing.T) *[]time.Duration {
+	delays := make([]time.Duration, 0)
+	sleep = func(delay time.Duration) {
+		delays = append(delays, delay)
+	}
+	t.Cleanup(func() {
+		sleep = time.Sleep
+	})
+
+	return &delays
+}
+
+func TestRetryFailedSingletonProvider(t *testing.T) {
+	delays := recordSleeps(t)
+	collection := InitServiceCollection()
+
+	attempts := 0
+
+	err := AddSingleton[TestType](collection, func(s *Scope) any {
+		attempts++
+		if attempts < 3 {
+			return errNotReady
+		}
+		return TestType{
+			counter: attempts,
+		}
+	}, WithRetry(RetryPolicy{
+		MaxAttempts:    5,
+		InitialBackoff: 10 * time.Millisecond,
+	}))
+	assert.Nil(t, err)
+
+	collection.Lock()
+
+	scope, err := collection.CreateScope()
+	assert.Nil(t, err)
+
+	testType, err := GetService[TestType](scope)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, testType.counter)
+	assert.Equal(t, []time.Duration{10 * time.Millisecond, 20 * time.Millisecond}, *delays)
+}
+
+func TestFailedSingletonIsNotCached(t *testing.T) {
+	collection := InitServiceCollection()
+
+	attempts := 0
+
+	err := AddSingleton[TestType](collection, func(s *Scope) any {
+		attempts++
+		if attempts == 1 {
+			return errNotReady
+		}
+		return TestType{
+			counter: attempts,
+		}
+	})
+	assert.Nil(t, err)
+
+	collection.Lock()
+
+	scope, err := collection.CreateScope()
+	assert.Nil(t, err)
+
+	_, err = GetService[TestType](scope)
+	assert.NotNil(t, err)
+	assert.True(t, errors.Is(err, errNotReady))
+	assert.Equal(t, fmt.Errorf("failed to initialize service dependency_injection.TestType: %w", errNotReady), err)
+
+	testType, err := GetService[TestType](scope)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, testType.counter)
+}
+
+func TestRetryStopsOnNotRetryableError(t *testing.T) {
+	delays := recordSleeps(t)
+	collection := InitServiceCollection()
+
+	attempts := 0
+	errInvalidConfig := errors.New("invalid config")
+
+	err := AddScoped[TestType](collection, func(s *Scope) any {
+		attempts++
+		if attempts == 1 {
+			return errNotReady
+		}
+		return errInvalidConfig
+	}, WithRetry(RetryPolicy{
+		MaxAttempts:    5,
+		InitialBackoff: time.Millisecond,
+		Retryable: func(err error) bool {
+			return errors.Is(err, errNotReady)
+		},
+	}))
+	assert.Nil(t, err)
+
+	collection.Lock()
+
+	scope, err := collection.CreateScope()
+	assert.Nil(t, err)
+
+	_, err = GetService[TestType](scope)
+	assert.NotNil(t, err)
+	assert.Equal(t, fmt.Errorf("failed to initialize service dependency_injection.TestType after 2 attempts: %w", errInvalidConfig), err)
+	assert.Equal(t, 2, attempts)
+	assert.Len(t, *delays, 1)
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := RetryPolicy{
+		InitialBackoff: 100 * time.Millisecond,
+		MaxBackoff:     time.Second,
+		Multiplier:     3,
+	}
+
+	assert.Equal(t, 100*time.Millisecond, policy.backoff(2))
+	assert.Equal(t, 300*time.Millisecond, policy.backoff(3))
+	assert.Equal(t, 900*time.Millisecond, policy.backoff(4))
+	assert.Equal(t, time.Second, policy.backoff(5))
+	assert.Equal(t, time.Second, policy.backoff(50))
+
+	policy.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		delay := policy.backoff(3)
+		assert.GreaterOrEqual(t, delay, 150*time.Millisecond)
+		assert.LessOrEqual(t, delay, 300*time.Millisecond)
+	}
+}
@@ -1,85 +1,481 @@
 package dependency_injection
 
 import (
+	"context"
 	"fmt"
-	log "github.com/sirupsen/logrus"
 	"reflect"
 	"sync"
+	"sync/atomic"
 )
 
 // Scope is a struct to request services from service collection.
 // Each scope has an isolated object pool for scoped services but will use a shared object pool singleton services.
 // To initialize Scope struct please CreateScope method of ServiceCollection.
 type Scope struct {
+	// State of the scope which is shared with the scopes that are passed to providers while initializing services.
+	*scopeState
+	// The service which is being initialized by the provider that received this scope, nil for scopes created by CreateScope.
+	resolving *ServiceType
+	// Observation of the resolution of the service which is being initialized.
+	observation *observation
+	// The scope that requested the service which is being initialized, nil for scopes created by CreateScope.
+	// Callers form the chain of services which are being initialized while the provider that received this scope runs.
+	caller *Scope
+	// Unique ID of the scope in the application.
+	id uint64
+	// Generation of the state when the scope is created, the scope is closed when the state has an other generation.
+	scopeGeneration uint64
+}
+
+// scopeState is the state of a scope which is shared between the scope and the scopes that are passed to providers.
+// States of closed scopes are reused by new scopes of the collection, so the scope is identified by its generation.
+type scopeState struct {
+	// Generation of the state which is incremented when its scope is closed, it's read atomically.
+	// It's the first field to keep it 64-bit aligned for atomic operations.
+	generation uint64
 	// The ServiceCollection that Scope was created for.
 	collection *ServiceCollection
 	// The object pool that will be used to retrieve scoped services if the service was initialized before.
 	// All new provided scoped services will store in this object pool for future services requests.
-	scopeServicePool map[reflect.Type]any
+	// It's indexed by service ID and allocated on first use, reused scopes keep it.
+	scopeServicePool []any
+	// Instances of pooled services which are borrowed by the scope and will be returned to their pools on Close.
+	borrowedServices []borrowedService
+	// Instances of scoped services in the order they are initialized.
+	scopedServices []any
+	// Context of the unit of work that the scope is created for.
+	ctx context.Context
+	// State of the scope that this scope is created from by CreateChild, nil for scopes created by collections.
+	parent *scopeState
+	// Services which are overridden in the scope and its children by Override.
+	overrides map[reflect.Type]*ServiceType
+	// Indicates that child scopes are created from the scope, so the state can't be reused.
+	retained bool
+	// Indicates that the scope has overrides, it's read atomically so scopes without overrides don't take the mutex.
+	overridden uint32
 	// A mutex to handle data race while providing or initializing scoped services.
 	mutex sync.RWMutex
 }
 
+// ID returns the unique ID of the scope in the application.
+func (s *Scope) ID() uint64 {
+	return s.id
+}
+
+// Context returns the context that the scope is created with, providers can use it to access request values.
+// It's context.Background for scopes created by CreateScope. The context of a closed scope is canceled,
+// so references to the scope that are kept after Close don't see the context of the scope that reuses its state.
+func (s *Scope) Context() context.Context {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	if !s.current() {
+		return closedScopeContext
+	}
+
+	return s.ctx
+}
+
+// resolvingScope returns a scope for the provider of given service which shares the state of the scope.
+func (s *Scope) resolvingScope(serviceType *ServiceType, o *observation) *Scope {
+	return &Scope{
+		scopeState:      s.scopeState,
+		id:              s.id,
+		resolving:       serviceType,
+		observation:     o,
+		caller:          s,
+		scopeGeneration: s.scopeGeneration,
+	}
+}
+
+// depth returns the number of services which are being initialized while the provider that received this scope runs.
+func (s *Scope) depth() int {
+	depth := 0
+	for caller := s.caller; caller != nil; caller = caller.caller {
+		depth++
+	}
+
+	return depth
+}
+
+// isResolving reports whether given service is being initialized by the scope or its callers.
+func (s *Scope) isResolving(serviceType *ServiceType) bool {
+	for current := s; current != nil; current = current.caller {
+		if current.resolving == serviceType {
+			return true
+		}
+	}
+
+	return false
+}
+
+// instance returns the instance of a service in the scope pool by service ID, the mutex of the scope must be held.
+func (state *scopeState) instance(id int) (any, bool) {
+	if id >= len(state.scopeServicePool) {
+		return nil, false
+	}
+
+	value := state.scopeServicePool[id]
+
+	return value, value != nil
+}
+
+// setInstance stores the instance of a service in the scope pool by service ID, nil removes the instance.
+// The mutex of the scope must be held.
+func (state *scopeState) setInstance(id int, value any) {
+	if id >= len(state.scopeServicePool) {
+		if value == nil {
+			return
+		}
+
+		size := serviceIDCount()
+		if size <= id {
+			size = id + 1
+		}
+		pool := make([]any, size)
+		copy(pool, state.scopeServicePool)
+		state.scopeServicePool = pool
+	}
+
+	state.scopeServicePool[id] = value
+}
+
+// borrowedService is an instance of a pooled service which is borrowed from the object pool of the service.
+type borrowedService struct {
+	serviceType *ServiceType
+	value       any
+}
+
 // Initialize or retrieve singleton services from ServiceCollection singleton object pool.
-func provideSingletonService[T any](s *Scope, reflectType reflect.Type, serviceType *ServiceType) T {
-	log.Debugf("Injecting signleton service <%v>\n", reflectType.String())
+func provideSingletonService[T any](s *Scope, reflectType reflect.Type, serviceType *ServiceType, o *observation) (T, error) {
+	s.debug("Injecting singleton service", serviceType)
 
-	s.collection.mutex.RLock()
-	value, available := s.collection.singletonServicePool[reflectType]
-	s.collection.mutex.RUnlock()
+	// Singletons without ttl never expire, so their initialized instance is retrieved without locks.
+	if serviceType.ttl == 0 {
+		if value, built := serviceType.loadInstance(); built {
+			s.debug("Value retrieved from singleton pool", serviceType)
+			o.cacheHit()
+			return value.(T), nil
+		}
+	}
+
+	collection := serviceType.collection
+
+	collection.mutex.RLock()
+	value, available := collection.singletonServicePool[reflectType]
+	expired := serviceType.ttl > 0 && !now().Before(serviceType.expiresAt)
+	collection.mutex.RUnlock()
+
+	if available && !expired {
+		s.debug("Value retrieved from singleton pool", serviceType)
+		o.cacheHit()
+		return value.(T), nil
+	}
 
 	if available {
-		log.Debugf("Value retrived from singleton pool for service <%v>\n", reflectType.String())
-		return value.(T)
+		return refreshSingletonService[T](s, reflectType, serviceType, o, value)
+	}
+
+	return buildSingletonService[T](s, reflectType, serviceType, o)
+}
+
+// buildSingletonService initializes a singleton service which isn't initialized yet. The service is initialized once
+// even if it's requested concurrently, other requests wait and retrieve the initialized instance.
+// Failed initializations aren't cached, so the next waiting request initializes the service again.
+func buildSingletonService[T any](s *Scope, reflectType reflect.Type, serviceType *ServiceType, o *observation) (T, error) {
+	if s.isResolving(serviceType) {
+		// The provider of the service requested the service, waiting for itself would never end.
+		var t T
+		return t, fmt.Errorf("circular dependency of singleton service %v", reflectType.String())
+	}
+
+	serviceType.buildMutex.Lock()
+	defer serviceType.buildMutex.Unlock()
+
+	collection := serviceType.collection
+
+	collection.mutex.RLock()
+	value, available := collection.singletonServicePool[reflectType]
+	collection.mutex.RUnlock()
+
+	if available {
+		// An other request initialized the service while this request was waiting.
+		s.debug("Value retrieved from singleton pool", serviceType)
+		o.cacheHit()
+		return value.(T), nil
+	}
+
+	value, err := buildService[T](s.singletonScope(serviceType), reflectType, serviceType, o)
+	if err != nil {
+		var t T
+		return t, err
+	}
+
+	collection.mutex.Lock()
+	collection.singletonServicePool[reflectType] = value
+	serviceType.storeInstance(value)
+	serviceType.expiresAt = now().Add(serviceType.ttl)
+	collection.mutex.Unlock()
+
+	s.debug("Providing singleton value", serviceType)
+
+	return value.(T), nil
+}
+
+// Initialize expired singleton services again, the expired value will be retrieved while an other request is initializing it.
+func refreshSingletonService[T any](s *Scope, reflectType reflect.Type, serviceType *ServiceType, o *observation, expiredValue any) (T, error) {
+	collection := serviceType.collection
+
+	collection.mutex.Lock()
+	if currentValue, available := collection.singletonServicePool[reflectType]; available && now().Before(serviceType.expiresAt) {
+		// An other request initialized the service in the meantime.
+		collection.mutex.Unlock()
+		o.cacheHit()
+		return currentValue.(T), nil
+	}
+	if serviceType.refreshing {
+		collection.mutex.Unlock()
+		s.debug("Expired value retrieved from singleton pool while refreshing", serviceType)
+		o.cacheHit()
+		return expiredValue.(T), nil
+	}
+	serviceType.refreshing = true
+	collection.mutex.Unlock()
+
+	value, err := buildService[T](s.singletonScope(serviceType), reflectType, serviceType, o)
+	if err != nil {
+		// Keep the expired value, so the next request will try to initialize the service again.
+		collection.mutex.Lock()
+		serviceType.refreshing = false
+		collection.mutex.Unlock()
+
+		var t T
+		return t, err
+	}
+
+	collection.mutex.Lock()
+	oldValue, available := collection.singletonServicePool[reflectType]
+	collection.singletonServicePool[reflectType] = value
+	serviceType.storeInstance(value)
+	serviceType.expiresAt = now().Add(serviceType.ttl)
+	serviceType.refreshing = false
+	borrowed := serviceType.borrowed
+	serviceType.borrowed = false
+	collection.mutex.Unlock()
+
+	if available && !borrowed && serviceType.dispose != nil {
+		serviceType.dispose(oldValue)
 	}
 
-	value = serviceType.provider(s)
+	s.debug("Refreshing singleton value", serviceType)
 
-	s.collection.mutex.Lock()
-	s.collection.singletonServicePool[reflectType] = value
-	s.collection.mutex.Unlock()
+	return value.(T), nil
+}
 
-	log.Debugf("Providing signleton value for service <%v>\n", reflectType.String())
+// singletonScope returns the scope that providers of a singleton service should use.
+// Singletons which are inherited from a parent collection are initialized in a scope of the parent,
+// so they don't depend on the services which are overridden in the child collection.
+// Singletons are shared by all scopes, so they are initialized in a new scope too if the scope has overrides.
+func (s *Scope) singletonScope(serviceType *ServiceType) *Scope {
+	if serviceType.collection == s.collection && !s.hasOverrides() {
+		return s
+	}
 
-	return value.(T)
+	scope := serviceType.collection.newScope()
+	scope.ctx = s.Context()
+	scope.observation = s.observation
+	// Keep the chain of services which are being initialized, so depths and circular dependencies are the same.
+	scope.resolving = s.resolving
+	scope.caller = s.caller
+
+	return scope
 }
 
 // Initialize or retrieve scoped service from Scope object pool.
-func provideScopedService[T any](s *Scope, reflectType reflect.Type, serviceType *ServiceType) T {
-	log.Debugf("Injecting scoped service <%v>\n", reflectType.String())
+func provideScopedService[T any](s *Scope, reflectType reflect.Type, serviceType *ServiceType, o *observation) (T, error) {
+	s.debug("Injecting scoped service", serviceType)
 
 	s.mutex.RLock()
-	value, available := s.scopeServicePool[reflectType]
+	value, available := s.instance(serviceType.id)
+	current := s.current()
 	s.mutex.RUnlock()
 
+	if !current {
+		var t T
+		return t, errScopeClosed()
+	}
+
 	if available {
-		log.Debugf("Value retrived from scope pool for service <%v>\n", reflectType.String())
-		return value.(T)
+		s.debug("Value retrieved from scope pool", serviceType)
+		o.cacheHit()
+		return value.(T), nil
 	}
 
-	value = serviceType.provider(s)
+	value, err := buildService[T](s, reflectType, serviceType, o)
+	if err != nil {
+		var t T
+		return t, err
+	}
 
 	s.mutex.Lock()
-	s.scopeServicePool[reflectType] = value
+	if !s.current() {
+		// The scope is closed while the service was being initialized, the state may belong to an other scope now.
+		s.mutex.Unlock()
+		var t T
+		return t, errScopeClosed()
+	}
+	s.setInstance(serviceType.id, value)
+	s.scopedServices = append(s.scopedServices, value)
 	s.mutex.Unlock()
 
-	log.Debugf("Providing scoped value for service <%v>\n", reflectType.String())
+	s.debug("Providing scoped value", serviceType)
 
-	return value.(T)
+	return value.(T), nil
 }
 
 // Initialize transient services.
-func provideTransientService[T any](s *Scope, reflectType reflect.Type, serviceType *ServiceType) T {
-	log.Debugf("Providing value to transient service <%v>\n", reflectType.String())
-	return serviceType.provider(s).(T)
+func provideTransientService[T any](s *Scope, reflectType reflect.Type, serviceType *ServiceType, o *observation) (T, error) {
+	s.debug("Providing transient value", serviceType)
+
+	value, err := buildService[T](s, reflectType, serviceType, o)
+	if err != nil {
+		var t T
+		return t, err
+	}
+
+	return value.(T), nil
+}
+
+// Borrow pooled services from their object pool and keep them in Scope object pool until the scope closes.
+func providePooledService[T any](s *Scope, reflectType reflect.Type, serviceType *ServiceType, o *observation) (T, error) {
+	s.debug("Injecting pooled service", serviceType)
+
+	s.mutex.RLock()
+	value, available := s.instance(serviceType.id)
+	current := s.current()
+	s.mutex.RUnlock()
+
+	if !current {
+		var t T
+		return t, errScopeClosed()
+	}
+
+	if available {
+		s.debug("Value retrieved from scope pool", serviceType)
+		o.cacheHit()
+		return value.(T), nil
+	}
+
+	value, borrowed := serviceType.pool.get()
+	if !borrowed {
+		var err error
+		if value, err = buildService[T](s, reflectType, serviceType, o); err != nil {
+			var t T
+			return t, err
+		}
+	}
+
+	s.mutex.Lock()
+	if !s.current() {
+		// The scope is closed while the service was being initialized, the state may belong to an other scope now.
+		s.mutex.Unlock()
+		serviceType.release(value)
+		var t T
+		return t, errScopeClosed()
+	}
+	if existing, exists := s.instance(serviceType.id); exists {
+		// An other goroutine borrowed the service in the meantime, so return this one to the pool.
+		s.mutex.Unlock()
+		serviceType.release(value)
+		o.cacheHit()
+		return existing.(T), nil
+	}
+	s.setInstance(serviceType.id, value)
+	s.borrowedServices = append(s.borrowedServices, borrowedService{
+		serviceType: serviceType,
+		value:       value,
+	})
+	s.mutex.Unlock()
+
+	if borrowed {
+		s.debug("Providing pooled value borrowed from pool", serviceType)
+		o.cacheHit()
+	} else {
+		s.debug("Providing new pooled value", serviceType)
+	}
+
+	return value.(T), nil
+}
+
+// Close ends the scope and returns all borrowed instances of pooled services to their object pools.
+// Requesting services from a closed scope will return an error. The state of the scope is reused by new scopes,
+// so references to the scope that are kept after Close, e.g. by services, return errors instead of services of
+// other scopes.
+func (s *Scope) Close() error {
+	s.mutex.Lock()
+	if !s.current() {
+		s.mutex.Unlock()
+		return fmt.Errorf("scope is already closed")
+	}
+
+	atomic.AddUint64(&s.generation, 1)
+	s.mutex.Unlock()
+
+	atomic.AddInt64(&s.collection.liveScopes, -1)
+	s.collection.untrackScope(s)
+
+	for _, borrowed := range s.borrowedServices {
+		borrowed.serviceType.release(borrowed.value)
+	}
+
+	s.recycle()
+
+	return nil
+}
+
+// checks if the scope is closed to avoid providing services after the scope ended
+func (s *Scope) checkClosed() error {
+	if !s.current() {
+		return errScopeClosed()
+	}
+
+	return nil
+}
+
+// errScopeClosed is the error of requesting services from a closed scope.
+func errScopeClosed() error {
+	return fmt.Errorf("scope is closed, you can't request services from it")
 }
 
 // GetService is responsible to retrieve or initialize requested service based on it's lifetime.
 func GetService[T any](s *Scope) (T, error) {
+	if err := s.checkClosed(); err != nil {
+		var t T
+		return t, err
+	}
+
 	reflectType := getReflectType[T]()
 
-	serviceType, exists := s.collection.registeredServicePool[reflectType]
+	if s.resolving != nil {
+		s.resolving.recordDependency(reflectType)
+	}
+
+	serviceType, exists := s.lookupOverride(reflectType)
+	if !exists {
+		serviceType, exists = s.collection.lookup(reflectType)
+	}
+
+	o := s.startObservation(reflectType, serviceType)
+
+	value, err := resolveService[T](s, reflectType, serviceType, exists, o)
+
+	o.end(err)
+
+	return value, err
+}
 
+// resolveService retrieves or initializes a service based on it's lifetime.
+func resolveService[T any](s *Scope, reflectType reflect.Type, serviceType *ServiceType, exists bool, o *observation) (T, error) {
 	if !exists {
 		var t T
 		return t, fmt.Errorf("service %v is not registered in service collection", reflectType.String())
@@ -87,11 +483,13 @@ func GetService[T any](s *Scope) (T, error) {
 
 	switch serviceType.lifetime {
 	case SINGLETON:
-		return provideSingletonService[T](s, reflectType, serviceType), nil
+		return provideSingletonService[T](s, reflectType, serviceType, o)
 	case SCOPED:
-		return provideScopedService[T](s, reflectType, serviceType), nil
+		return provideScopedService[T](s, reflectType, serviceType, o)
 	case TRANSIENT:
-		return provideTransientService[T](s, reflectType, serviceType), nil
+		return provideTransientService[T](s, reflectType, serviceType, o)
+	case POOLED:
+		return providePooledService[T](s, reflectType, serviceType, o)
 	}
 
 	var t T
@@ -0,0 +1,58 @@
+package dependency_injection
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// acquireScopeState takes the state of a closed scope from the scope pool of the collection or allocates a new one.
+func (collection *ServiceCollection) acquireScopeState() *scopeState {
+	state, reused := collection.scopePool.Get().(*scopeState)
+	if !reused {
+		state = &scopeState{
+			collection: collection,
+		}
+	}
+
+	state.ctx = context.Background()
+
+	return state
+}
+
+// closedScopeContext is the context of closed scopes.
+var closedScopeContext = func() context.Context {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	return ctx
+}()
+
+// current reports whether the state still belongs to the scope, it's false when the scope is closed
+// even if the state is reused by an other scope.
+func (s *Scope) current() bool {
+	return atomic.LoadUint64(&s.generation) == s.scopeGeneration
+}
+
+// recycle resets the state of a closed scope and returns it to the scope pool of the collection.
+// States which have overrides or child scopes are left to the garbage collector, since child scopes keep using them.
+func (state *scopeState) recycle() {
+	state.mutex.Lock()
+	if state.retained || atomic.LoadUint32(&state.overridden) == 1 {
+		state.mutex.Unlock()
+		return
+	}
+
+	// Keep the storage of scoped services, so the next scope doesn't allocate it again.
+	for i := range state.scopeServicePool {
+		state.scopeServicePool[i] = nil
+	}
+	for i := range state.scopedServices {
+		state.scopedServices[i] = nil
+	}
+	state.scopedServices = state.scopedServices[:0]
+	state.borrowedServices = state.borrowedServices[:0]
+	state.ctx = nil
+	state.parent = nil
+	state.mutex.Unlock()
+
+	state.collection.scopePool.Put(state)
+}
@@ -1,21 +1,51 @@
 package dependency_injection
 
 import (
+	"context"
 	"fmt"
 	"reflect"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
+// lastScopeID is the ID of the last created scope, it's used to assign unique IDs to scopes.
+var lastScopeID uint64
+
 // ServiceCollection is collection of services to use them in your application.
 // This struct contains two pools, first one for registered services and second one for provided singleton services.
-// You may need to initialize ServiceCollection only one time in your application
+// You may need to initialize ServiceCollection only one time in your application,
+// use CreateChild to derive collections that override some registrations, like per tenant collections.
 type ServiceCollection struct {
+	// Number of scopes which are created by CreateScope and are not closed yet.
+	// It's the first field to keep it 64-bit aligned for atomic operations.
+	liveScopes int64
 	// Pool of registered services
 	registeredServicePool map[reflect.Type]*ServiceType
 	// A shared object pool between different scopes to collect provided singleton services.
 	// This pool will be used to retrieve singleton objects if they are initialized before.
 	// All new initialized singleton services will be stored in this object pool.
 	singletonServicePool map[reflect.Type]any
+	// Registry of the collection which is published by Lock, it's nil until the collection is locked.
+	registry atomic.Value
+	// Parent collection that registrations which aren't registered in this collection are inherited from.
+	parent *ServiceCollection
+	// Names of the modules which are installed in the collection.
+	installedModules map[string]bool
+	// Name of the module which is registering services right now.
+	installingModule string
+	// Logger of the collection and its scopes.
+	logger Logger
+	// Observers that are notified about resolutions of services.
+	observers []Observer
+	// Metrics of resolutions, nil if metrics are not enabled.
+	metrics *metricsObserver
+	// Scopes which are created and not closed yet by their ID, nil if scope tracking is not enabled.
+	activeScopes map[uint64]*Scope
+	// Sources of configuration which options are bound from, see AddOptions.
+	configSources []ConfigSource
+	// States of closed scopes which are reused by new scopes.
+	scopePool sync.Pool
 	// Lock of service collection
 	locked bool
 	// A mutex to handle data race while providing or initializing singleton services.
@@ -27,50 +57,215 @@ func InitServiceCollection() *ServiceCollection {
 	return &ServiceCollection{
 		registeredServicePool: make(map[reflect.Type]*ServiceType),
 		singletonServicePool:  make(map[reflect.Type]any),
+		installedModules:      make(map[string]bool),
+		logger:                NopLogger(),
 		locked:                false,
 		mutex:                 sync.RWMutex{},
 	}
 }
 
+// CreateChild creates a child collection which inherits all registrations of the collection.
+// Services that are registered in the child override inherited registrations and their singletons are kept in the child,
+// while singletons of inherited services are shared with the parent.
+// Child collections are locked independently, but scopes can be created only when all ancestors of the child are locked too.
+func (collection *ServiceCollection) CreateChild() *ServiceCollection {
+	child := InitServiceCollection()
+	child.parent = collection
+	child.logger = collection.logger
+	child.observers = append([]Observer(nil), collection.observers...)
+	child.metrics = collection.metrics
+
+	return child
+}
+
 // AddSingleton registers a service as singleton
-func AddSingleton[T any](collection *ServiceCollection, provider func(s *Scope) any) error {
+func AddSingleton[T any](collection *ServiceCollection, provider func(s *Scope) any, opts ...ServiceOption) error {
+	if err := collection.checkLock(); err != nil {
+		return err
+	}
+
+	collection.add(getReflectType[T](), SINGLETON, provider, opts...)
+
+	return nil
+}
+
+// AddSingletonWithTTL registers a service as singleton which will be initialized again after given ttl.
+// While the expired instance is being initialized again, other requests will retrieve the expired instance.
+func AddSingletonWithTTL[T any](collection *ServiceCollection, ttl time.Duration, provider func(s *Scope) any, opts ...ServiceOption) error {
 	if err := collection.checkLock(); err != nil {
 		return err
 	}
 
-	collection.add(getReflectType[T](), SINGLETON, provider)
+	reflectType := getReflectType[T]()
+
+	if ttl <= 0 {
+		return fmt.Errorf("ttl of service %v must be greater than zero", reflectType.String())
+	}
+
+	serviceType := &ServiceType{
+		lifetime: SINGLETON,
+		provider: provider,
+		ttl:      ttl,
+	}
+
+	for _, opt := range opts {
+		opt(serviceType)
+	}
+
+	collection.register(reflectType, serviceType)
 
 	return nil
 }
 
+// Invalidate removes the initialized instance of a singleton service, so it will be initialized again on next request.
+func Invalidate[T any](collection *ServiceCollection) error {
+	reflectType := getReflectType[T]()
+
+	serviceType, exists := collection.lookup(reflectType)
+
+	if !exists {
+		return fmt.Errorf("service %v is not registered in service collection", reflectType.String())
+	}
+
+	if serviceType.lifetime != SINGLETON {
+		return fmt.Errorf("service %v is not registered as singleton", reflectType.String())
+	}
+
+	owner := serviceType.collection
+	owner.mutex.Lock()
+	value, available := owner.singletonServicePool[reflectType]
+	delete(owner.singletonServicePool, reflectType)
+	serviceType.storeInstance(nil)
+	borrowed := serviceType.borrowed
+	serviceType.borrowed = false
+	owner.mutex.Unlock()
+
+	if available && !borrowed && serviceType.dispose != nil {
+		serviceType.dispose(value)
+	}
+
+	return nil
+}
+
+// replace replaces the provider of a registered service in the collection, even after locking the collection,
+// and returns a function that restores the previous registration. The service keeps its lifetime and options,
+// and its initialized singleton is removed, so the new provider is used on next request.
+// It's intended for tests, see ditest package, and must not be called while the collection provides services.
+func (collection *ServiceCollection) replace(reflectType reflect.Type, provider func(s *Scope) any) (func(), error) {
+	current, exists := collection.lookup(reflectType)
+
+	if !exists {
+		return nil, fmt.Errorf("service %v is not registered in service collection", reflectType.String())
+	}
+
+	replacement := current.copyFor(collection)
+	replacement.provider = provider
+
+	collection.mutex.Lock()
+	previous, registered := collection.registeredServicePool[reflectType]
+	collection.registeredServicePool[reflectType] = replacement
+	delete(collection.singletonServicePool, reflectType)
+	if collection.locked {
+		collection.publishRegistry()
+	}
+	collection.mutex.Unlock()
+
+	restore := func() {
+		collection.mutex.Lock()
+		defer collection.mutex.Unlock()
+
+		if registered {
+			collection.registeredServicePool[reflectType] = previous
+			previous.storeInstance(nil)
+		} else {
+			delete(collection.registeredServicePool, reflectType)
+		}
+		delete(collection.singletonServicePool, reflectType)
+		if collection.locked {
+			collection.publishRegistry()
+		}
+	}
+
+	return restore, nil
+}
+
 // AddScoped registers a service as scoped
-func AddScoped[T any](collection *ServiceCollection, provider func(s *Scope) any) error {
+func AddScoped[T any](collection *ServiceCollection, provider func(s *Scope) any, opts ...ServiceOption) error {
 	if err := collection.checkLock(); err != nil {
 		return err
 	}
 
-	collection.add(getReflectType[T](), SCOPED, provider)
+	collection.add(getReflectType[T](), SCOPED, provider, opts...)
 
 	return nil
 }
 
 // AddTransient registers a service as transient
-func AddTransient[T any](collection *ServiceCollection, provider func(scope *Scope) any) error {
+func AddTransient[T any](collection *ServiceCollection, provider func(scope *Scope) any, opts ...ServiceOption) error {
 	if err := collection.checkLock(); err != nil {
 		return err
 	}
 
-	collection.add(getReflectType[T](), TRANSIENT, provider)
+	collection.add(getReflectType[T](), TRANSIENT, provider, opts...)
+
+	return nil
+}
+
+// AddPooled registers a service as pooled.
+// The reset function will be called on every instance before returning it to the object pool, it can be nil.
+func AddPooled[T any](collection *ServiceCollection, provider func(scope *Scope) any, reset func(T), opts ...ServiceOption) error {
+	if err := collection.checkLock(); err != nil {
+		return err
+	}
+
+	reflectType := getReflectType[T]()
+	serviceType := &ServiceType{
+		lifetime: POOLED,
+		provider: provider,
+		poolSize: defaultPoolSize,
+	}
+
+	for _, opt := range opts {
+		opt(serviceType)
+	}
+
+	if serviceType.poolSize <= 0 {
+		return fmt.Errorf("pool size of service %v must be greater than zero", reflectType.String())
+	}
+
+	serviceType.pool = newObjectPool(serviceType.poolSize)
+	if reset != nil {
+		serviceType.reset = func(value any) {
+			reset(value.(T))
+		}
+	}
+
+	collection.register(reflectType, serviceType)
 
 	return nil
 }
 
-// Add a service to service collection with given lifetime and provider
-func (collection *ServiceCollection) add(t reflect.Type, lifetime int, provider func(scope *Scope) any) {
-	collection.registeredServicePool[t] = &ServiceType{
+// Add a service to service collection with given lifetime, provider and options
+func (collection *ServiceCollection) add(t reflect.Type, lifetime int, provider func(scope *Scope) any, opts ...ServiceOption) {
+	serviceType := &ServiceType{
 		lifetime: lifetime,
 		provider: provider,
 	}
+
+	for _, opt := range opts {
+		opt(serviceType)
+	}
+
+	collection.register(t, serviceType)
+}
+
+// register stores the configuration of a service in registered services pool
+func (collection *ServiceCollection) register(t reflect.Type, serviceType *ServiceType) {
+	serviceType.reflectType = t
+	serviceType.id = assignServiceID(t)
+	serviceType.collection = collection
+	serviceType.module = collection.installingModule
+	collection.registeredServicePool[t] = serviceType
 }
 
 // checks lock of the service collection to avoid adding more services when application starts to work
@@ -82,20 +277,50 @@ func (collection *ServiceCollection) checkLock() error {
 	return nil
 }
 
-// Lock preparing service collection to create scope
+// Lock preparing service collection to create scope.
+// Registrations are published in an immutable registry, so they are looked up without locks after locking.
 func (collection *ServiceCollection) Lock() {
+	collection.mutex.Lock()
+	defer collection.mutex.Unlock()
+
 	collection.locked = true
+	collection.publishRegistry()
 }
 
 // CreateScope creates a new scope in application to retrieve services
 func (collection *ServiceCollection) CreateScope() (*Scope, error) {
+	return collection.CreateScopeWithContext(context.Background())
+}
+
+// CreateScopeWithContext creates a new scope for the unit of work of given context, like an incoming request.
+// Providers can access the context with Context method of the scope.
+func (collection *ServiceCollection) CreateScopeWithContext(ctx context.Context) (*Scope, error) {
 	if !collection.locked {
 		return nil, fmt.Errorf("you have to lock service collection to create a scope")
 	}
 
+	for parent := collection.parent; parent != nil; parent = parent.parent {
+		if !parent.locked {
+			return nil, fmt.Errorf("you have to lock parent service collection to create a scope")
+		}
+	}
+
+	atomic.AddInt64(&collection.liveScopes, 1)
+
+	scope := collection.newScope()
+	scope.ctx = ctx
+	collection.trackScope(scope)
+
+	return scope, nil
+}
+
+// newScope initialize a scope for the collection without checking its lock
+func (collection *ServiceCollection) newScope() *Scope {
+	state := collection.acquireScopeState()
+
 	return &Scope{
-		collection:       collection,
-		scopeServicePool: make(map[reflect.Type]any),
-		mutex:            sync.RWMutex{},
-	}, nil
+		scopeState:      state,
+		id:              atomic.AddUint64(&lastScopeID, 1),
+		scopeGeneration: atomic.LoadUint64(&state.generation),
+	}
 }
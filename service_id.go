@@ -0,0 +1,80 @@
+package dependency_injection
+
+import (
+	"reflect"
+	"sync"
+	"sync/atomic"
+)
+
+// serviceIDTable assigns dense integer IDs to service types, so scope pools are slices indexed by the ID of
+// the registration instead of maps. Go has no storage per generic instantiation, so finding the ID of T would hash
+// its reflect type anyway, that's why resolutions find registrations in the registry by type and use their ID.
+// It's immutable and replaced by a new table when a type gets an ID.
+type serviceIDTable struct {
+	// IDs of service types.
+	ids map[reflect.Type]int
+	// Service types by their ID.
+	types []reflect.Type
+}
+
+var (
+	// serviceIDs is the current serviceIDTable, it's read without locks.
+	serviceIDs atomic.Value
+	// serviceIDMutex serializes assigning IDs to new service types.
+	serviceIDMutex sync.Mutex
+)
+
+// currentServiceIDs returns the current table of service IDs.
+func currentServiceIDs() *serviceIDTable {
+	table, _ := serviceIDs.Load().(*serviceIDTable)
+	if table == nil {
+		return &serviceIDTable{}
+	}
+
+	return table
+}
+
+// findServiceID returns the ID of a service type, false if the type never got an ID, so it's never registered.
+func findServiceID(t reflect.Type) (int, bool) {
+	id, exists := currentServiceIDs().ids[t]
+	return id, exists
+}
+
+// assignServiceID returns the ID of a service type and assigns a new ID if the type doesn't have one.
+func assignServiceID(t reflect.Type) int {
+	if id, exists := findServiceID(t); exists {
+		return id
+	}
+
+	serviceIDMutex.Lock()
+	defer serviceIDMutex.Unlock()
+
+	current := currentServiceIDs()
+	if id, exists := current.ids[t]; exists {
+		return id
+	}
+
+	next := &serviceIDTable{
+		ids:   make(map[reflect.Type]int, len(current.ids)+1),
+		types: append(append(make([]reflect.Type, 0, len(current.types)+1), current.types...), t),
+	}
+	for existing, id := range current.ids {
+		next.ids[existing] = id
+	}
+	id := len(current.types)
+	next.ids[t] = id
+
+	serviceIDs.Store(next)
+
+	return id
+}
+
+// serviceIDCount returns the number of service types that have an ID.
+func serviceIDCount() int {
+	return len(currentServiceIDs().types)
+}
+
+// serviceTypeByID returns the service type of an ID.
+func serviceTypeByID(id int) reflect.Type {
+	return currentServiceIDs().types[id]
+}
@@ -0,0 +1,29 @@
+package dependency_injection
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+type TestIdentified struct{}
+
+func TestAssignServiceID(t *testing.T) {
+	reflectType := getReflectType[TestIdentified]()
+
+	id := assignServiceID(reflectType)
+	assert.Equal(t, id, assignServiceID(reflectType), "ID of a type should be stable")
+	assert.Equal(t, reflectType, serviceTypeByID(id))
+	assert.Greater(t, serviceIDCount(), id)
+
+	other := assignServiceID(getReflectType[*TestIdentified]())
+	assert.NotEqual(t, id, other)
+
+	collection := InitServiceCollection()
+	err := AddScoped[TestIdentified](collection, func(s *Scope) any {
+		return TestIdentified{}
+	})
+	assert.Nil(t, err)
+
+	serviceType, _ := collection.lookup(reflectType)
+	assert.Equal(t, id, serviceType.id)
+}
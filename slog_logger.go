@@ -0,0 +1,35 @@
+//go:build go1.21
+
+package dependency_injection
+
+import (
+	"context"
+	"log/slog"
+)
+
+// slogLogger is a Logger that writes logs with log/slog.
+type slogLogger struct {
+	logger *slog.Logger
+}
+
+// NewSlogLogger returns a Logger that writes debug logs with given slog logger.
+func NewSlogLogger(logger *slog.Logger) Logger {
+	return &slogLogger{
+		logger: logger,
+	}
+}
+
+// Enabled reports whether the slog logger handles debug level.
+func (l *slogLogger) Enabled() bool {
+	return l.logger.Enabled(context.Background(), slog.LevelDebug)
+}
+
+// Debug writes a debug log with fields as slog attributes.
+func (l *slogLogger) Debug(msg string, fields ...Field) {
+	attrs := make([]slog.Attr, len(fields))
+	for i, field := range fields {
+		attrs[i] = slog.Any(field.Key, field.Value)
+	}
+
+	l.logger.LogAttrs(context.Background(), slog.LevelDebug, msg, attrs...)
+}
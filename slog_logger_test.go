@@ -0,0 +1,47 @@
+//go:build go1.21
+
+package dependency_injection
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"log/slog"
+	"testing"
+)
+
+func TestSlogLogger(t *testing.T) {
+	buffer := bytes.Buffer{}
+	handler := slog.NewTextHandler(&buffer, &slog.HandlerOptions{
+		Level: slog.LevelDebug,
+		ReplaceAttr: func(groups []string, attr slog.Attr) slog.Attr {
+			if attr.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return attr
+		},
+	})
+
+	collection := InitServiceCollection()
+	assert.Nil(t, collection.SetLogger(NewSlogLogger(slog.New(handler))))
+
+	err := AddTransient[TestType](collection, func(s *Scope) any {
+		return TestType{}
+	})
+	assert.Nil(t, err)
+
+	collection.Lock()
+
+	scope, err := collection.CreateScope()
+	assert.Nil(t, err)
+
+	_, err = GetService[TestType](scope)
+	assert.Nil(t, err)
+
+	assert.Equal(t, fmt.Sprintf("level=DEBUG msg=\"Providing transient value\" service=dependency_injection.TestType lifetime=transient scope_id=%v\n", scope.ID()), buffer.String())
+}
+
+func TestDisabledSlogLogger(t *testing.T) {
+	logger := NewSlogLogger(slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil)))
+	assert.False(t, logger.Enabled())
+}
@@ -0,0 +1,25 @@
+package dependency_injection
+
+import (
+	"github.com/ashkanabd/go-di/internal/testhook"
+	"reflect"
+)
+
+func init() {
+	testhook.Clone = func(collection any) any {
+		original := collection.(*ServiceCollection)
+		clone := original.Clone()
+		if original.locked {
+			clone.Lock()
+		}
+
+		return clone
+	}
+	testhook.Replace = func(collection any, serviceType reflect.Type, provider any) (func(), error) {
+		// Interfaces are registered by their pointer type, see getReflectType.
+		if serviceType.Kind() == reflect.Interface {
+			serviceType = reflect.PointerTo(serviceType)
+		}
+		return collection.(*ServiceCollection).replace(serviceType, provider.(func(s *Scope) any))
+	}
+}
@@ -0,0 +1,98 @@
+package dependency_injection
+
+import (
+	"context"
+	"time"
+)
+
+// Tracer starts spans, it mirrors the Start method of OpenTelemetry tracers,
+// so an OpenTelemetry tracer can be used with a thin wrapper.
+type Tracer interface {
+	// Start starts a span with given name as a child of the span in ctx and returns a context that contains the new span.
+	Start(ctx context.Context, spanName string) (context.Context, Span)
+}
+
+// Span is a traced operation, it mirrors the subset of OpenTelemetry spans that resolutions use.
+type Span interface {
+	// SetAttribute sets an attribute of the span.
+	SetAttribute(key string, value any)
+	// AddEvent adds an event to the span.
+	AddEvent(name string)
+	// RecordError records an error of the span.
+	RecordError(err error)
+	// End completes the span.
+	End()
+}
+
+// Attribute keys of resolution spans.
+const (
+	AttributeService  = "di.service"
+	AttributeLifetime = "di.lifetime"
+	AttributeScopeID  = "di.scope_id"
+	AttributeDepth    = "di.depth"
+	AttributeCacheHit = "di.cache_hit"
+)
+
+// tracingObserver is an Observer that creates a span for each resolution.
+type tracingObserver struct {
+	tracer Tracer
+}
+
+// NewTracingObserver returns an Observer that creates a span for each resolution with given tracer.
+// Resolutions that are requested by providers are traced as children of the resolution of the provider's service,
+// other resolutions are traced as children of the span in the context of their scope, like the span of the request.
+func NewTracingObserver(tracer Tracer) Observer {
+	return &tracingObserver{
+		tracer: tracer,
+	}
+}
+
+// StartResolution starts the span of a resolution.
+func (observer *tracingObserver) StartResolution(event ResolutionEvent, parent ResolutionObserver) ResolutionObserver {
+	ctx := event.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if parentSpan, ok := parent.(*spanResolution); ok {
+		ctx = parentSpan.ctx
+	}
+
+	ctx, span := observer.tracer.Start(ctx, "di.resolve "+event.Service)
+	span.SetAttribute(AttributeService, event.Service)
+	span.SetAttribute(AttributeLifetime, event.Lifetime)
+	span.SetAttribute(AttributeScopeID, event.ScopeID)
+	span.SetAttribute(AttributeDepth, event.Depth)
+
+	return &spanResolution{
+		ctx:  ctx,
+		span: span,
+	}
+}
+
+// spanResolution is the span of a resolution.
+type spanResolution struct {
+	ctx  context.Context
+	span Span
+}
+
+func (r *spanResolution) CacheHit() {
+	r.span.SetAttribute(AttributeCacheHit, true)
+}
+
+func (r *spanResolution) ProviderStart() {
+	r.span.AddEvent("provider_start")
+}
+
+func (r *spanResolution) ProviderEnd(_ time.Duration, err error) {
+	r.span.AddEvent("provider_end")
+	if err != nil {
+		r.span.RecordError(err)
+	}
+}
+
+func (r *spanResolution) End(_ time.Duration, err error) {
+	if err != nil {
+		r.span.RecordError(err)
+	}
+	r.span.End()
+}
@@ -0,0 +1,150 @@
+package dependency_injection
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+// setNow replaces current time of the package until the end of the test.
+func setNow(t *testing.T, current *time.Time) {
+	now = func() time.Time {
+		return *current
+	}
+	t.Cleanup(func() {
+		now = time.Now
+	})
+}
+
+func TestGetSingletonServiceWithTTL(t *testing.T) {
+	current := time.Now()
+	setNow(t, &current)
+
+	collection := InitServiceCollection()
+
+	counter := 0
+	disposed := make([]int, 0)
+
+	err := AddSingletonWithTTL[TestType](collection, time.Minute, func(s *Scope) any {
+		counter++
+		return TestType{
+			counter: counter,
+		}
+	}, WithDispose(func(value TestType) {
+		disposed = append(disposed, value.counter)
+	}))
+	assert.Nil(t, err)
+
+	collection.Lock()
+
+	scope, err := collection.CreateScope()
+	assert.Nil(t, err)
+
+	// Retrieve services for first time
+	testType, err := GetService[TestType](scope)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, testType.counter)
+
+	// Retrieve services again before expiration
+	current = current.Add(30 * time.Second)
+	testType, err = GetService[TestType](scope)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, testType.counter)
+	assert.Empty(t, disposed)
+
+	// Retrieve services after expiration
+	current = current.Add(30 * time.Second)
+	testType, err = GetService[TestType](scope)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, testType.counter)
+	assert.Equal(t, []int{1}, disposed)
+
+	// Retrieve services after invalidation
+	assert.Nil(t, Invalidate[TestType](collection))
+	assert.Equal(t, []int{1, 2}, disposed)
+
+	testType, err = GetService[TestType](scope)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, testType.counter)
+}
+
+func TestGetExpiredSingletonServiceWhileRefreshing(t *testing.T) {
+	current := time.Now()
+	setNow(t, &current)
+
+	collection := InitServiceCollection()
+
+	counter := 0
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	err := AddSingletonWithTTL[TestType](collection, time.Minute, func(s *Scope) any {
+		counter++
+		if counter > 1 {
+			close(started)
+			<-release
+		}
+		return TestType{
+			counter: counter,
+		}
+	})
+	assert.Nil(t, err)
+
+	collection.Lock()
+
+	scope, err := collection.CreateScope()
+	assert.Nil(t, err)
+
+	testType, err := GetService[TestType](scope)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, testType.counter)
+
+	current = current.Add(time.Minute)
+
+	refreshed := make(chan TestType)
+	go func() {
+		value, _ := GetService[TestType](scope)
+		refreshed <- value
+	}()
+
+	// Expired value should be retrieved while an other request is refreshing it
+	<-started
+	testType, err = GetService[TestType](scope)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, testType.counter)
+
+	close(release)
+	assert.Equal(t, 2, (<-refreshed).counter)
+
+	testType, err = GetService[TestType](scope)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, testType.counter)
+}
+
+func TestAddSingletonWithInvalidTTL(t *testing.T) {
+	collection := InitServiceCollection()
+
+	err := AddSingletonWithTTL[TestType](collection, 0, func(s *Scope) any {
+		return TestType{}
+	})
+	assert.NotNil(t, err)
+	assert.Equal(t, fmt.Errorf("ttl of service dependency_injection.TestType must be greater than zero"), err)
+}
+
+func TestInvalidateNotSingletonService(t *testing.T) {
+	collection := InitServiceCollection()
+
+	err := AddScoped[TestType](collection, func(s *Scope) any {
+		return TestType{}
+	})
+	assert.Nil(t, err)
+
+	err = Invalidate[TestType](collection)
+	assert.NotNil(t, err)
+	assert.Equal(t, fmt.Errorf("service dependency_injection.TestType is not registered as singleton"), err)
+
+	err = Invalidate[TestInterface](collection)
+	assert.NotNil(t, err)
+	assert.Equal(t, fmt.Errorf("service *dependency_injection.TestInterface is not registered in service collection"), err)
+}